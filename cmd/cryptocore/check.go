@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"cryptcore/internal/cli"
+	myhash "cryptcore/internal/hash"
+)
+
+// checkSums: dgst --check. Каждая строка списка пересчитывается своим алгоритмом:
+// из тега BSD, из дайджеста sha256-tree или из --algorithm для формата GNU. Вывод и код
+// возврата — как у sha256sum -c, включая --quiet, --status и --strict. Результаты
+// строк пишутся в stdout, ошибки и итоговые предупреждения — в stderr.
+func checkSums(opts *cli.DgstOptions, stdout, stderr io.Writer) int {
+	var list io.Reader = os.Stdin
+	if opts.Check != "-" {
+		f, err := os.Open(opts.Check)
+		if err != nil {
+			fmt.Fprintf(stderr, "dgst: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		list = f
+	}
+
+	var valid, misformatted, unreadable, mismatched int
+	sc := bufio.NewScanner(list)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry, err := cli.ParseChecksumLine(line)
+		var e *cli.DgstOptions
+		if err == nil {
+			e, err = checkEntryOptions(opts, entry)
+		}
+		if err != nil {
+			misformatted++
+			continue
+		}
+		valid++
+
+		got, err := digestFile(e, entry.Path)
+		switch {
+		case err != nil:
+			unreadable++
+			fmt.Fprintf(stderr, "dgst: %v\n", err)
+			if !opts.Status {
+				fmt.Fprintf(stdout, "%s: FAILED open or read\n", entry.Path)
+			}
+		case got != entry.Digest:
+			mismatched++
+			if !opts.Status {
+				fmt.Fprintf(stdout, "%s: FAILED\n", entry.Path)
+			}
+		case !opts.Quiet && !opts.Status:
+			fmt.Fprintf(stdout, "%s: OK\n", entry.Path)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		fmt.Fprintf(stderr, "dgst: %s: %v\n", opts.Check, err)
+		return 1
+	}
+
+	if valid == 0 {
+		fmt.Fprintf(stderr, "dgst: %s: no properly formatted checksum lines found\n", opts.Check)
+		return 1
+	}
+	if !opts.Status {
+		if misformatted > 0 {
+			fmt.Fprintf(stderr, "dgst: WARNING: %s improperly formatted\n", plural(misformatted, "line is", "lines are"))
+		}
+		if unreadable > 0 {
+			fmt.Fprintf(stderr, "dgst: WARNING: %s not be read\n", plural(unreadable, "listed file could", "listed files could"))
+		}
+		if mismatched > 0 {
+			fmt.Fprintf(stderr, "dgst: WARNING: %s NOT match\n", plural(mismatched, "computed checksum did", "computed checksums did"))
+		}
+	}
+	if mismatched > 0 || unreadable > 0 || (opts.Strict && misformatted > 0) {
+		return 1
+	}
+	return 0
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// checkEntryOptions подбирает параметры dgst для строки списка и проверяет, что длина
+// дайджеста соответствует алгоритму. Для XOF и blake3 длина без --length берётся из строки.
+func checkEntryOptions(opts *cli.DgstOptions, entry cli.ChecksumLine) (*cli.DgstOptions, error) {
+	e := *opts
+	if entry.Algorithm != "" {
+		e.Algorithm = entry.Algorithm
+	}
+	if strings.HasPrefix(entry.Digest, myhash.TreeAlgorithm+"-") {
+		if entry.Algorithm != "" {
+			return nil, fmt.Errorf("tree digest in a BSD-tag line")
+		}
+		chunkSize, _, _, err := myhash.ParseTreeDigest(entry.Digest)
+		if err != nil {
+			return nil, err
+		}
+		e.Algorithm, e.ChunkSize = myhash.TreeAlgorithm, chunkSize
+		return &e, nil
+	}
+
+	n := len(entry.Digest) / 2
+	var want int
+	switch {
+	case e.Algorithm == myhash.TreeAlgorithm:
+		return nil, fmt.Errorf("sha256-tree lines must carry the chunk size")
+	case e.Algorithm == "par-sha256":
+		want = 32
+	case e.Algorithm == "blake3" || myhash.IsXOF(e.Algorithm):
+		if e.Length == 0 {
+			e.Length = n
+		}
+		want = e.Length
+	default:
+		newHash, err := myhash.Digest(e.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		want = newHash().Size()
+	}
+	if n != want {
+		return nil, fmt.Errorf("%s digest must be %d bytes, got %d", e.Algorithm, want, n)
+	}
+	return &e, nil
+}
+
+func digestFile(opts *cli.DgstOptions, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return digestReader(opts, f)
+}
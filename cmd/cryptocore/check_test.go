@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cryptcore/internal/cli"
+)
+
+func TestCheckSums(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(a, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("hello\n"))
+	good := hex.EncodeToString(sum[:]) + "  " + a
+	bad := strings.Repeat("00", 32) + "  " + a
+	missing := hex.EncodeToString(sum[:]) + "  " + filepath.Join(dir, "missing.txt")
+
+	cases := []struct {
+		name   string
+		lines  []string
+		flags  []string
+		code   int
+		stdout string // подстрока; "" — stdout должен быть пустым
+		stderr string
+	}{
+		{"ok", []string{good}, nil, 0, a + ": OK", ""},
+		{"ok quiet", []string{good}, []string{"--quiet"}, 0, "", ""},
+		{"ok status", []string{good}, []string{"--status"}, 0, "", ""},
+		{"mismatch", []string{bad}, nil, 1, a + ": FAILED", "1 computed checksum did NOT match"},
+		{"mismatch strict", []string{bad}, []string{"--strict"}, 1, a + ": FAILED", "1 computed checksum did NOT match"},
+		{"mismatch status", []string{bad}, []string{"--status"}, 1, "", ""},
+		{"missing", []string{good, missing}, nil, 1, "missing.txt: FAILED open or read", "1 listed file could not be read"},
+		{"missing strict", []string{missing}, []string{"--strict"}, 1, "missing.txt: FAILED open or read", "1 listed file could not be read"},
+		// как у sha256sum --status: ошибка открытия всё равно попадает в stderr
+		{"missing status", []string{missing}, []string{"--status"}, 1, "", "missing.txt"},
+		{"malformed", []string{"garbage", good}, nil, 0, a + ": OK", "1 line is improperly formatted"},
+		{"malformed strict", []string{"garbage", good}, []string{"--strict"}, 1, a + ": OK", "1 line is improperly formatted"},
+		{"malformed status", []string{"garbage", good}, []string{"--status"}, 0, "", ""},
+		{"no valid lines", []string{"garbage", "# comment", ""}, nil, 1, "", "no properly formatted checksum lines found"},
+		{"no valid lines status", []string{"garbage"}, []string{"--status"}, 1, "", "no properly formatted checksum lines found"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			list := filepath.Join(t.TempDir(), "SUMS")
+			if err := os.WriteFile(list, []byte(strings.Join(c.lines, "\n")+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			opts, err := cli.ParseDgstArgs(append([]string{"--check", list}, c.flags...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var stdout, stderr bytes.Buffer
+			if code := checkSums(opts, &stdout, &stderr); code != c.code {
+				t.Errorf("exit %d, want %d\nstdout: %s\nstderr: %s", code, c.code, stdout.String(), stderr.String())
+			}
+			if c.stdout == "" && stdout.Len() != 0 {
+				t.Errorf("unexpected stdout: %q", stdout.String())
+			}
+			if !strings.Contains(stdout.String(), c.stdout) {
+				t.Errorf("stdout %q does not contain %q", stdout.String(), c.stdout)
+			}
+			if c.stderr == "" && stderr.Len() != 0 {
+				t.Errorf("unexpected stderr: %q", stderr.String())
+			}
+			if !strings.Contains(stderr.String(), c.stderr) {
+				t.Errorf("stderr %q does not contain %q", stderr.String(), c.stderr)
+			}
+		})
+	}
+}
+
+// Вывод dgst -r должен читаться dgst --check, в том числе для имён с переводом строки
+// и обратной косой чертой.
+func TestHashFilesCheckRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"plain.txt", "x\ny", `back\slash`} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Skipf("file name %q unsupported: %v", name, err)
+		}
+	}
+	sums := filepath.Join(t.TempDir(), "SUMS")
+	opts, err := cli.ParseDgstArgs([]string{"-r", dir, "--output", sums})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := hashFiles(opts); code != 0 {
+		t.Fatalf("hashFiles exit %d", code)
+	}
+	data, err := os.ReadFile(sums)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Fatalf("want 3 lines, got %d:\n%s", lines, data)
+	}
+
+	opts, err = cli.ParseDgstArgs([]string{"--check", sums, "--strict"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := checkSums(opts, &stdout, &stderr); code != 0 || strings.Count(stdout.String(), ": OK") != 3 || stderr.Len() != 0 {
+		t.Errorf("exit %d\nstdout: %s\nstderr: %s", code, stdout.String(), stderr.String())
+	}
+}
@@ -0,0 +1,576 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"cryptcore/internal/blockcipher"
+	"cryptcore/internal/cli"
+	"cryptcore/internal/crypto"
+	"cryptcore/internal/fs"
+	myhash "cryptcore/internal/hash"
+	"cryptcore/internal/kdf"
+)
+
+func handleEncryption(args []string) {
+	opts, err := cli.ParseArgs(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+	if err := blockcipher.SetAESImplementation(opts.AESImpl); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	outputPath := opts.OutputPath
+	if outputPath == "" {
+		if opts.Encrypt {
+			outputPath = fs.DefaultEncryptedName(opts.InputPath)
+		} else {
+			outputPath = fs.DefaultDecryptedName(opts.InputPath)
+		}
+	}
+	if fs.SameFile(opts.InputPath, outputPath) {
+		fmt.Fprintln(os.Stderr, "error: --output must differ from --input (data is streamed)")
+		os.Exit(1)
+	}
+
+	in, err := fs.Open(opts.InputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error reading input file:", err)
+		os.Exit(1)
+	}
+	defer in.Close()
+
+	out, err := fs.Create(outputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error writing output file:", err)
+		os.Exit(1)
+	}
+
+	err = runCipher(opts, in, out)
+	if cerr := out.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("error writing output file: %w", cerr)
+	}
+	if err != nil {
+		// частично записанный результат не оставляем
+		os.Remove(outputPath)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runCipher: потоковое шифрование/расшифрование in -> out.
+func runCipher(opts *cli.Options, in io.Reader, out io.Writer) error {
+	switch {
+	case opts.Legacy:
+		return runLegacy(opts, in, out)
+	case opts.Encrypt:
+		return encryptContainer(opts, in, out)
+	default:
+		return decryptContainer(opts, in, out)
+	}
+}
+
+// encryptContainer: [заголовок][шифртекст], параметры расшифрования берутся из заголовка.
+func encryptContainer(opts *cli.Options, in io.Reader, out io.Writer) error {
+	h := &crypto.Header{Cipher: crypto.CanonicalCipher(opts.Algorithm), Mode: opts.Mode}
+
+	var key []byte
+	var err error
+	if opts.Password != "" {
+		h.KDF = crypto.KDFPBKDF2SHA256
+		h.Iterations = uint32(opts.Iterations)
+		h.Salt, err = crypto.GenerateRandomBytes(16)
+		if err != nil {
+			return fmt.Errorf("error generating salt: %w", err)
+		}
+		fmt.Printf("[INFO] Using PBKDF2 with generated salt: %x\n", h.Salt)
+		key = passwordKey(opts.Password, h.Salt, opts.Iterations, opts.KeySize)
+	} else {
+		key, err = rawKey(opts, opts.KeySize)
+		if err != nil {
+			return err
+		}
+	}
+
+	ivLen := crypto.IVSize(h.Cipher, h.Mode)
+	var chunkSize int
+	if opts.Stream {
+		chunkSize = opts.ChunkSize
+		// вместо IV хранится prefix nonce чанков
+		ivLen = crypto.StreamNoncePrefixSize(h.Cipher, h.Mode)
+		chunk := make([]byte, 4)
+		binary.BigEndian.PutUint32(chunk, uint32(opts.ChunkSize))
+		h.Params = append(h.Params, crypto.HeaderParam{ID: crypto.HeaderParamChunkSize, Value: chunk})
+	}
+	if opts.Mode == "xts" {
+		sector := make([]byte, 4)
+		binary.BigEndian.PutUint32(sector, uint32(opts.SectorSize))
+		h.Params = append(h.Params, crypto.HeaderParam{ID: crypto.HeaderParamSectorSize, Value: sector})
+	}
+	if opts.MAC != "" {
+		id, err := crypto.EtMMACID(opts.MAC)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		h.Params = append(h.Params, crypto.HeaderParam{ID: crypto.HeaderParamMAC, Value: []byte{id}})
+	}
+	if opts.Padding != "" && opts.Padding != crypto.PaddingPKCS7 {
+		id, err := crypto.PaddingID(opts.Padding)
+		if err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+		h.Params = append(h.Params, crypto.HeaderParam{ID: crypto.HeaderParamPadding, Value: []byte{id}})
+	}
+	if ivLen > 0 {
+		h.IV, err = crypto.GenerateRandomBytes(ivLen)
+		if err != nil {
+			return fmt.Errorf("crypto error: cannot generate IV: %w", err)
+		}
+	}
+
+	header, err := h.Marshal()
+	if err != nil {
+		return fmt.Errorf("crypto error: %w", err)
+	}
+	if _, err := out.Write(header); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	return processBody(opts, bodyParams{
+		algorithm:  h.Cipher,
+		mode:       h.Mode,
+		key:        key,
+		iv:         h.IV,
+		header:     header,
+		chunkSize:  chunkSize,
+		mac:        opts.MAC,
+		sectorSize: opts.SectorSize,
+		padding:    opts.Padding,
+	}, in, out)
+}
+
+// decryptContainer: режим, шифр и параметры KDF читаются из заголовка;
+// явно указанные --algorithm/--mode должны с ним совпадать.
+func decryptContainer(opts *cli.Options, in io.Reader, out io.Writer) error {
+	h, err := crypto.ReadHeader(in)
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+	if opts.Algorithm != "" && crypto.CanonicalCipher(opts.Algorithm) != h.Cipher {
+		return fmt.Errorf("error: --algorithm %s does not match file header (%s)", opts.Algorithm, h.Cipher)
+	}
+	if opts.Mode != "" && opts.Mode != h.Mode {
+		return fmt.Errorf("error: --mode %s does not match file header (%s)", opts.Mode, h.Mode)
+	}
+	if opts.UseAADFlag && !crypto.IsAEAD(h.Cipher, h.Mode) {
+		return errors.New("error: --aad is only supported for AEAD ciphers (gcm, siv, chacha20-poly1305)")
+	}
+	if err := crypto.CheckAlgorithmMode(h.Cipher, h.Mode); err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	ivLen := crypto.IVSize(h.Cipher, h.Mode)
+	var chunkSize int
+	if v, ok := h.Param(crypto.HeaderParamChunkSize); ok {
+		if len(v) != 4 || !crypto.IsAEAD(h.Cipher, h.Mode) {
+			return errors.New("error: invalid chunk size in file header")
+		}
+		// те же границы, что и при шифровании: буфер чанка выделяется целиком
+		chunkSize = int(binary.BigEndian.Uint32(v))
+		if chunkSize < crypto.MinStreamChunkSize || chunkSize > crypto.MaxStreamChunkSize {
+			return fmt.Errorf("error: chunk size %d in file header is out of range (%d..%d)", chunkSize, crypto.MinStreamChunkSize, crypto.MaxStreamChunkSize)
+		}
+		ivLen = crypto.StreamNoncePrefixSize(h.Cipher, h.Mode)
+	}
+	if len(h.IV) != ivLen {
+		return fmt.Errorf("error: invalid IV length %d in file header", len(h.IV))
+	}
+	var macName string
+	if v, ok := h.Param(crypto.HeaderParamMAC); ok {
+		if len(v) != 1 || !etmModes[h.Mode] {
+			return errors.New("error: invalid MAC parameter in file header")
+		}
+		if macName, err = crypto.EtMMACName(v[0]); err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+	}
+
+	var sectorSize int
+	if v, ok := h.Param(crypto.HeaderParamSectorSize); ok {
+		if len(v) != 4 || h.Mode != "xts" {
+			return errors.New("error: invalid sector size in file header")
+		}
+		sectorSize = int(binary.BigEndian.Uint32(v))
+	} else if h.Mode == "xts" {
+		return errors.New("error: xts file header has no sector size")
+	}
+
+	var padding string
+	if v, ok := h.Param(crypto.HeaderParamPadding); ok {
+		if len(v) != 1 || (h.Mode != "ecb" && h.Mode != "cbc") {
+			return errors.New("error: invalid padding parameter in file header")
+		}
+		if padding, err = crypto.PaddingName(v[0]); err != nil {
+			return fmt.Errorf("error: %w", err)
+		}
+	}
+
+	keySize, err := crypto.KeySize(h.Cipher, h.Mode)
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+
+	var key []byte
+	switch h.KDF {
+	case crypto.KDFPBKDF2SHA256:
+		if opts.Password == "" {
+			return errors.New("error: file was encrypted with a password; use --password")
+		}
+		if h.Iterations == 0 || h.Iterations > cli.MaxIterations {
+			return fmt.Errorf("error: invalid PBKDF2 iteration count %d in file header (1..%d)", h.Iterations, cli.MaxIterations)
+		}
+		fmt.Printf("[INFO] Using PBKDF2 with extracted salt: %x\n", h.Salt)
+		key = passwordKey(opts.Password, h.Salt, int(h.Iterations), keySize)
+	default:
+		if opts.KeyHex == "" {
+			return errors.New("error: file was encrypted with a raw key; use --key")
+		}
+		key, err = rawKey(opts, keySize)
+		if err != nil {
+			return err
+		}
+	}
+
+	header, err := h.Marshal()
+	if err != nil {
+		return fmt.Errorf("error: %w", err)
+	}
+	return processBody(opts, bodyParams{
+		algorithm:  h.Cipher,
+		mode:       h.Mode,
+		key:        key,
+		iv:         h.IV,
+		header:     header,
+		chunkSize:  chunkSize,
+		mac:        macName,
+		sectorSize: sectorSize,
+		padding:    padding,
+	}, in, out)
+}
+
+// runLegacy: старый формат без заголовка [salt, если --password][IV/nonce][ciphertext];
+// режим и число итераций задаются только флагами.
+func runLegacy(opts *cli.Options, in io.Reader, out io.Writer) error {
+	var key []byte
+	var err error
+	if opts.Password != "" {
+		salt := make([]byte, 16)
+		if opts.Encrypt {
+			salt, err = crypto.GenerateRandomBytes(16)
+			if err != nil {
+				return fmt.Errorf("error generating salt: %w", err)
+			}
+			// соль в начало файла
+			if _, err := out.Write(salt); err != nil {
+				return fmt.Errorf("error writing output file: %w", err)
+			}
+			fmt.Printf("[INFO] Using PBKDF2 with generated salt: %x\n", salt)
+		} else {
+			if _, err := io.ReadFull(in, salt); err != nil {
+				return errors.New("error: input file too short to contain salt")
+			}
+			fmt.Printf("[INFO] Using PBKDF2 with extracted salt: %x\n", salt)
+		}
+		key = passwordKey(opts.Password, salt, opts.Iterations, opts.KeySize)
+	} else {
+		key, err = rawKey(opts, opts.KeySize)
+		if err != nil {
+			return err
+		}
+	}
+
+	var iv []byte
+	if n := crypto.IVSize(opts.Algorithm, opts.Mode); n > 0 {
+		switch {
+		case opts.Encrypt:
+			iv, err = crypto.GenerateRandomBytes(n)
+			if err != nil {
+				return fmt.Errorf("crypto error: cannot generate IV: %w", err)
+			}
+			if _, err := out.Write(iv); err != nil {
+				return fmt.Errorf("error writing output file: %w", err)
+			}
+		case opts.UseIVFlag:
+			iv, err = crypto.ParseHexIV(opts.IVHex)
+			if err != nil {
+				return fmt.Errorf("crypto error: %w", err)
+			}
+		default:
+			iv = make([]byte, n)
+			if _, err := io.ReadFull(in, iv); err != nil {
+				return errors.New("crypto error: ciphertext file too short to contain IV")
+			}
+		}
+	}
+
+	return processBody(opts, bodyParams{
+		algorithm:  opts.Algorithm,
+		mode:       opts.Mode,
+		key:        key,
+		iv:         iv,
+		sectorSize: opts.SectorSize,
+		padding:    opts.Padding,
+	}, in, out)
+}
+
+// bodyParams: всё, что нужно для обработки шифртекста после заголовка/IV.
+type bodyParams struct {
+	algorithm  string
+	mode       string
+	key        []byte
+	iv         []byte // IV, nonce или prefix nonce чанков
+	header     []byte // сериализованный заголовок (nil для --legacy)
+	chunkSize  int    // > 0 — сегментированный AEAD
+	mac        string // encrypt-then-MAC для cbc/cfb/ofb/ctr
+	sectorSize int    // размер сектора xts
+	padding    string // выравнивание ecb/cbc; пусто — PKCS#7
+}
+
+// etmModes: режимы, для которых допустим --mac.
+var etmModes = map[string]bool{"cbc": true, "cfb": true, "cfb8": true, "cfb1": true, "ofb": true, "ctr": true}
+
+// processBody: шифртекст после заголовка/IV. В AEAD-режимах header входит в AAD
+// перед пользовательским --aad; siv получает их отдельными компонентами AD.
+func processBody(opts *cli.Options, p bodyParams, in io.Reader, out io.Writer) error {
+	var w io.WriteCloser
+	var err error
+
+	switch {
+	case crypto.IsAEAD(p.algorithm, p.mode):
+		var userAAD []byte
+		if opts.UseAADFlag {
+			if userAAD, err = loadAAD(opts.AAD); err != nil {
+				return err
+			}
+		}
+		var ad [][]byte
+		if p.header != nil {
+			ad = append(ad, p.header)
+		}
+		if userAAD != nil {
+			ad = append(ad, userAAD)
+		}
+		aad := append(append([]byte(nil), p.header...), userAAD...)
+
+		var aead crypto.AEAD
+		if aead, err = crypto.NewAEAD(p.algorithm, p.mode, p.key); err != nil {
+			return fmt.Errorf("crypto error: %w", err)
+		}
+		if p.chunkSize == 0 {
+			return processAEAD(opts, aead, p.iv, ad, in, out)
+		}
+
+		// сегментированный AEAD: каждый чанк проверяется до выдачи
+		if opts.Encrypt {
+			w, err = crypto.NewStreamSealer(aead, p.iv, aad, p.chunkSize, out)
+		} else {
+			w, err = crypto.NewStreamOpener(aead, p.iv, aad, p.chunkSize, out)
+		}
+	case p.mac != "":
+		return processEtM(opts, p, in, out)
+	case p.mode == "xts":
+		w, err = crypto.NewXTSWriter(p.key, p.sectorSize, opts.Decrypt, out)
+	default:
+		w, err = newModeWriter(opts, p, p.key, out)
+	}
+	if err != nil {
+		return fmt.Errorf("crypto error: %w", err)
+	}
+
+	buf := make([]byte, 64*1024)
+	if _, err := io.CopyBuffer(w, in, buf); err != nil {
+		return fmt.Errorf("crypto error: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("crypto error: %w", err)
+	}
+	return nil
+}
+
+// processEtM: encrypt-then-MAC. При шифровании тег HMAC(header||IV||ciphertext) дописывается
+// в конец; при расшифровании шифртекст при проверке тега копируется во временный файл
+// и расшифровывается уже оттуда — так расшифровываются ровно те байты, что покрыл тег,
+// даже если --input изменится во время работы.
+func processEtM(opts *cli.Options, p bodyParams, in io.Reader, out io.Writer) error {
+	encKey, macKey, err := crypto.SplitEtMKeys(p.key, len(p.key), p.mac)
+	if err != nil {
+		return fmt.Errorf("crypto error: %w", err)
+	}
+	m, err := crypto.NewEtMMAC(p.mac, macKey)
+	if err != nil {
+		return fmt.Errorf("crypto error: %w", err)
+	}
+	m.Write(p.header) // IV входит в заголовок
+
+	buf := make([]byte, 64*1024)
+
+	if opts.Encrypt {
+		w, err := newModeWriter(opts, p, encKey, io.MultiWriter(out, m))
+		if err != nil {
+			return fmt.Errorf("crypto error: %w", err)
+		}
+		if _, err := io.CopyBuffer(w, in, buf); err != nil {
+			return fmt.Errorf("crypto error: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("crypto error: %w", err)
+		}
+		if _, err := out.Write(m.Sum(nil)); err != nil {
+			return fmt.Errorf("error writing output file: %w", err)
+		}
+		return nil
+	}
+
+	tmp, err := os.CreateTemp("", "cryptocore-etm-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	n, err := crypto.VerifyEtM(m, in, tmp)
+	if err != nil {
+		return fmt.Errorf("crypto error: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error reading temporary file: %w", err)
+	}
+
+	w, err := newModeWriter(opts, p, encKey, out)
+	if err != nil {
+		return fmt.Errorf("crypto error: %w", err)
+	}
+	if _, err := io.CopyBuffer(w, io.LimitReader(tmp, n), buf); err != nil {
+		return fmt.Errorf("crypto error: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("crypto error: %w", err)
+	}
+	return nil
+}
+
+// newModeWriter: поток ecb/cbc/cfb*/ofb/ctr; для ecb/cbc учитывает выравнивание p.padding.
+func newModeWriter(opts *cli.Options, p bodyParams, key []byte, out io.Writer) (io.WriteCloser, error) {
+	if crypto.IsCTS(p.padding) {
+		return crypto.NewCTSWriter(p.algorithm, p.mode, p.padding, key, p.iv, opts.Decrypt, out)
+	}
+	padding, err := crypto.NewPadding(p.padding)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Encrypt {
+		return crypto.NewPaddedEncryptWriter(p.algorithm, p.mode, padding, key, p.iv, out)
+	}
+	return crypto.NewPaddedDecryptWriter(p.algorithm, p.mode, padding, key, p.iv, out)
+}
+
+// maxAEADSize: предел открытого текста для AEAD без --stream. Больший файл шифруется
+// только с --stream; уже зашифрованный расшифровывается только с явным --in-memory.
+const maxAEADSize = 256 * 1024 * 1024
+
+// processAEAD: одиночный тег на весь файл — открытый текст нельзя выдать до проверки,
+// поэтому файл обрабатывается в памяти. Компоненты ad склеиваются в один AAD для всех
+// AEAD, кроме VectorAEAD (siv).
+func processAEAD(opts *cli.Options, aead crypto.AEAD, nonce []byte, ad [][]byte, in io.Reader, out io.Writer) error {
+	limit := maxAEADSize
+	if opts.Decrypt {
+		limit += aead.Overhead() // файл, зашифрованный на пределе, расшифровывается
+	}
+	data, err := io.ReadAll(io.LimitReader(in, int64(limit)+1))
+	if err != nil {
+		return fmt.Errorf("error reading input file: %w", err)
+	}
+	if len(data) > limit {
+		if opts.Encrypt {
+			return fmt.Errorf("error: input is larger than %d MiB; without --stream the whole file is kept in memory, use --stream", maxAEADSize>>20)
+		}
+		if !opts.InMemory {
+			return fmt.Errorf("error: input is larger than %d MiB and has a single tag, so it can only be decrypted in memory; pass --in-memory to allow it and re-encrypt with --stream", maxAEADSize>>20)
+		}
+		rest, err := io.ReadAll(in)
+		if err != nil {
+			return fmt.Errorf("error reading input file: %w", err)
+		}
+		data = append(data, rest...)
+	}
+
+	var result []byte
+	vec, isVector := aead.(crypto.VectorAEAD)
+	aad := bytes.Join(ad, nil)
+	switch {
+	case opts.Encrypt && isVector:
+		result = vec.SealVector(nil, nonce, data, ad)
+	case opts.Encrypt:
+		result = aead.Seal(nil, nonce, data, aad)
+	case isVector:
+		result, err = vec.OpenVector(nil, nonce, data, ad)
+	default:
+		// при ошибке аутентификации открытый текст не записывается
+		result, err = aead.Open(nil, nonce, data, aad)
+	}
+	if err != nil {
+		return fmt.Errorf("crypto error: %w", err)
+	}
+	if _, err := out.Write(result); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	return nil
+}
+
+// passwordKey: PBKDF2-HMAC-SHA256 (собственная реализация SHA-256).
+func passwordKey(password string, salt []byte, iterations, keySize int) []byte {
+	return kdf.Key(func() hash.Hash { return myhash.NewSHA256() }, []byte(password), salt, iterations, keySize)
+}
+
+// rawKey: ключ из --key; при шифровании без --key генерируется случайный.
+func rawKey(opts *cli.Options, keySize int) ([]byte, error) {
+	keyHex := opts.KeyHex
+	if opts.Encrypt && keyHex == "" {
+		newKey, err := crypto.GenerateRandomBytes(keySize)
+		if err != nil {
+			return nil, fmt.Errorf("error generating key: %w", err)
+		}
+		keyHex = hex.EncodeToString(newKey)
+		fmt.Printf("[INFO] Generated random key: %s\n", keyHex)
+	}
+
+	key, err := crypto.ParseHexKey(keyHex, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	return key, nil
+}
+
+// loadAAD: --aad принимает hex-строку; если это не hex — путь к файлу с AAD.
+// Пустая строка — пустой, но заданный AAD (не nil).
+func loadAAD(spec string) ([]byte, error) {
+	if spec == "" {
+		return []byte{}, nil
+	}
+	if aad, err := hex.DecodeString(spec); err == nil {
+		return aad, nil
+	}
+	aad, err := fs.ReadAll(spec)
+	if err != nil {
+		return nil, fmt.Errorf("--aad is neither hex nor a readable file: %w", err)
+	}
+	return aad, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"cryptcore/internal/cli"
+	"cryptcore/internal/fs"
+)
+
+// hashFiles: dgst над списком путей. Файлы хешируются в opts.Jobs горутинах, но строки
+// "<hex>  <путь>" выводятся в порядке списка — как только готов очередной файл.
+// Ошибка по одному файлу не останавливает остальные; код возврата тогда 1.
+func hashFiles(opts *cli.DgstOptions) int {
+	entries := fs.CollectFiles(opts.InputPaths, opts.Walk)
+
+	// par-sha256, sha256-tree и blake3 сами делят файл на чанки по горутинам; CPU делятся
+	// между одновременно хешируемыми файлами, иначе их было бы Jobs*NumCPU.
+	inFlight := min(opts.Jobs, len(entries))
+	if inFlight > 1 {
+		o := *opts
+		o.Workers = max(1, runtime.NumCPU()/inFlight)
+		opts = &o
+	}
+
+	type result struct {
+		index  int
+		digest string
+		err    error
+	}
+	jobs := make(chan int)
+	results := make(chan result, opts.Jobs)
+
+	var wg sync.WaitGroup
+	wg.Add(opts.Jobs)
+	for i := 0; i < opts.Jobs; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				e := entries[index]
+				if e.Err != nil {
+					results <- result{index: index, err: e.Err}
+					continue
+				}
+				digest, err := digestFile(opts, e.Path)
+				results <- result{index: index, digest: digest, err: err}
+			}
+		}()
+	}
+	go func() {
+		for i := range entries {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var out strings.Builder
+	failed := false
+	pending := map[int]result{}
+	next := 0
+	for res := range results {
+		pending[res.index] = res
+		for r, ok := pending[next]; ok; r, ok = pending[next] {
+			delete(pending, next)
+			next++
+			if r.err != nil {
+				failed = true
+				fmt.Fprintf(os.Stderr, "dgst: %s: %v\n", entries[r.index].Path, unwrapPathError(r.err))
+				continue
+			}
+			line := cli.FormatChecksumLine(r.digest, entries[r.index].Path)
+			if opts.OutputPath != "" {
+				out.WriteString(line)
+			} else {
+				fmt.Print(line)
+			}
+		}
+	}
+
+	if opts.OutputPath != "" {
+		if err := fs.WriteAll(opts.OutputPath, []byte(out.String())); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+			return 1
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// unwrapPathError убирает из *os.PathError повтор пути: он уже выведен перед ошибкой.
+func unwrapPathError(err error) error {
+	var pe *os.PathError
+	if errors.As(err, &pe) {
+		return pe.Err
+	}
+	return err
+}
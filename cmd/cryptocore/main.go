@@ -1,10 +1,8 @@
 package main
 
 import (
-	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
-	"hash"
 	"io"
 	"os"
 
@@ -30,6 +28,10 @@ func main() {
 		cli.HMACCmd(os.Args[2:])
 	case "derive":
 		handleDerive(os.Args[2:])
+	case "keywrap":
+		handleKeywrap(os.Args[2:])
+	case "verify-chunk":
+		handleVerifyChunk(os.Args[2:])
 	default:
 		// backward compatibility: encryption/decryption через флаги
 		if len(command) > 0 && command[0] == '-' {
@@ -49,6 +51,13 @@ func handleDgst(args []string) {
 		os.Exit(1)
 	}
 
+	if opts.Check != "" {
+		os.Exit(checkSums(opts, os.Stdout, os.Stderr))
+	}
+	if opts.Verify == "" && !opts.Proof {
+		os.Exit(hashFiles(opts))
+	}
+
 	f, err := os.Open(opts.InputPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error opening file: %v\n", err)
@@ -56,49 +65,155 @@ func handleDgst(args []string) {
 	}
 	defer f.Close()
 
-	var hashBytes []byte
-
-	if opts.Algorithm == "par-sha256" {
-		hashBytes, err = myhash.ParallelSHA256(f)
+	if opts.Verify != "" {
+		ok, err := myhash.VerifyTreeSHA256(f, opts.Verify, 0)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error hashing file (parallel): %v\n", err)
+			fmt.Fprintf(os.Stderr, "error hashing file: %v\n", err)
 			os.Exit(1)
 		}
-	} else {
-		var hasher hash.Hash
-
-		switch opts.Algorithm {
-		case "sha256":
-			hasher = myhash.NewSHA256()
-		case "sha512":
-			hasher = sha512.New()
-		default:
-			fmt.Fprintf(os.Stderr, "unsupported algorithm: %s\n", opts.Algorithm)
+		if !ok {
+			fmt.Printf("%s: FAILED\n", opts.InputPath)
 			os.Exit(1)
 		}
+		fmt.Printf("%s: OK\n", opts.InputPath)
+		return
+	}
 
-		buf := make([]byte, 32*1024)
-		if _, err := io.CopyBuffer(hasher, f, buf); err != nil {
-			fmt.Fprintf(os.Stderr, "error hashing file: %v\n", err)
+	proof, err := chunkProof(opts, f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dgst error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.OutputPath != "" {
+		if err := fs.WriteAll(opts.OutputPath, []byte(proof)); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
 			os.Exit(1)
 		}
-		hashBytes = hasher.Sum(nil)
+		return
 	}
+	fmt.Print(proof)
+}
 
-	hashStr := hex.EncodeToString(hashBytes)
-	output := fmt.Sprintf("%s  %s\n", hashStr, opts.InputPath)
+// digestReader хеширует r алгоритмом opts.Algorithm и возвращает дайджест в виде для вывода:
+// hex либо "sha256-tree-1M:<chunks>:<hex>".
+func digestReader(opts *cli.DgstOptions, r io.Reader) (string, error) {
+	var hashBytes []byte
+	var err error
 
-	if opts.OutputPath != "" {
-		if err := fs.WriteAll(opts.OutputPath, []byte(output)); err != nil {
-			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
-			os.Exit(1)
+	switch {
+	case opts.Algorithm == "par-sha256":
+		hashBytes, err = myhash.ParallelSHA256(r, opts.Workers)
+	case opts.Algorithm == myhash.TreeAlgorithm:
+		// размер и число чанков — часть дайджеста: без них дерево не пересчитать
+		return myhash.TreeDigest(r, opts.ChunkSize, opts.Workers)
+	case opts.Algorithm == "blake3":
+		hashBytes, err = blake3File(opts, r)
+	case myhash.IsXOF(opts.Algorithm):
+		xof, n, xerr := myhash.NewXOFByName(opts.Algorithm, []byte(opts.Customization))
+		if xerr != nil {
+			return "", xerr
 		}
-	} else {
-		fmt.Print(output)
+		if opts.Length > 0 {
+			n = opts.Length
+		}
+		if _, err = io.CopyBuffer(xof, r, make([]byte, 32*1024)); err == nil {
+			hashBytes = make([]byte, n)
+			xof.Read(hashBytes)
+		}
+	default:
+		newHash, herr := myhash.Digest(opts.Algorithm)
+		if herr != nil {
+			return "", herr
+		}
+		hasher := newHash()
+		if _, err = io.CopyBuffer(hasher, r, make([]byte, 32*1024)); err == nil {
+			hashBytes = hasher.Sum(nil)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hashBytes), nil
+}
+
+// chunkProof строит доказательство включения чанка opts.Chunk. Первой строкой идёт
+// комментарий с корнем — для справки: verify-chunk доверяет только своему --root.
+func chunkProof(opts *cli.DgstOptions, r io.Reader) (string, error) {
+	leaves, err := myhash.TreeLeaves(r, opts.ChunkSize, 0)
+	if err != nil {
+		return "", err
 	}
+	proof, err := myhash.MerkleInclusionProof(leaves, opts.Chunk)
+	if err != nil {
+		return "", err
+	}
+	root := myhash.MerkleRoot(leaves)
+	return fmt.Sprintf("# %s  %s\n%s", myhash.FormatTreeDigest(opts.ChunkSize, len(leaves), root[:]), opts.InputPath, proof), nil
 }
 
-// Sprint 7 (m7.html): cryptocore derive --password ... [--salt hex] [--iterations N] [--length L] --algorithm pbkdf2 [--output file]
+// verify-chunk --root sha256-tree-1M:<chunks>:<hex> --chunk N --proof <файл> --input <чанк>
+func handleVerifyChunk(args []string) {
+	opts, err := cli.ParseVerifyChunkArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verify-chunk error: %v\n", err)
+		os.Exit(1)
+	}
+
+	proofText, err := fs.ReadAll(opts.ProofPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading proof: %v\n", err)
+		os.Exit(1)
+	}
+	proof, err := myhash.ParseInclusionProof(proofText)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verify-chunk error: %v\n", err)
+		os.Exit(1)
+	}
+	chunk, err := fs.ReadAll(opts.InputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading input: %v\n", err)
+		os.Exit(1)
+	}
+
+	ok, err := myhash.VerifyChunk(chunk, opts.Chunk, proof, opts.Root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verify-chunk error: %v\n", err)
+		os.Exit(1)
+	}
+	if !ok {
+		fmt.Printf("%s: FAILED (chunk %d of %d)\n", opts.InputPath, proof.Index, proof.TreeSize)
+		os.Exit(1)
+	}
+	fmt.Printf("%s: OK (chunk %d of %d)\n", opts.InputPath, proof.Index, proof.TreeSize)
+}
+
+// blake3File: BLAKE3 с --key (keyed hash) или --context (derive-key); поддеревья по
+// myhash.ChunkSize считаются в opts.Workers горутинах, результат от их числа не зависит.
+func blake3File(opts *cli.DgstOptions, r io.Reader) ([]byte, error) {
+	var h *myhash.DigestBLAKE3
+	switch {
+	case opts.Key != nil:
+		var err error
+		if h, err = myhash.NewBLAKE3Keyed(opts.Key); err != nil {
+			return nil, err
+		}
+	case opts.Context != "":
+		h = myhash.NewBLAKE3DeriveKey(opts.Context)
+	default:
+		h = myhash.NewBLAKE3()
+	}
+	if err := myhash.ParallelBLAKE3(h, r, opts.Workers); err != nil {
+		return nil, err
+	}
+	out := make([]byte, h.Size())
+	if opts.Length > 0 {
+		out = make([]byte, opts.Length)
+	}
+	h.Finalize(out)
+	return out, nil
+}
+
+// Sprint 7 (m7.html): cryptocore derive --password ... [--salt hex] [--iterations N] [--length L] --algorithm pbkdf2 [--hash sha256] [--output file]
 // stdout: KEY_HEX SALT_HEX
 func handleDerive(args []string) {
 	opts, err := cli.ParseDeriveArgs(args)
@@ -123,9 +238,14 @@ func handleDerive(args []string) {
 		}
 	}
 
-	// PBKDF2-HMAC-SHA256 (ваша реализация kdf.Key, sha256 = myhash.NewSHA256)
+	// PBKDF2-HMAC-<hash> (kdf.Key над собственными хеш-функциями, по умолчанию sha256)
+	newHash, err := myhash.Digest(opts.Hash)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "derive error: %v\n", err)
+		os.Exit(1)
+	}
 	pass := []byte(opts.Password)
-	key := kdf.Key(func() hash.Hash { return myhash.NewSHA256() }, pass, salt, opts.Iterations, opts.Length)
+	key := kdf.Key(newHash, pass, salt, opts.Iterations, opts.Length)
 
 	// should: очистить пароль из памяти
 	for i := range pass {
@@ -144,109 +264,45 @@ func handleDerive(args []string) {
 	fmt.Printf("%s  %s\n", hex.EncodeToString(key), hex.EncodeToString(salt))
 }
 
-func handleEncryption(args []string) {
-	opts, err := cli.ParseArgs(args)
+// cryptocore keywrap --wrap|--unwrap --kek <hex> --input key.bin [--pad] [--output file]
+// stdout (без --output): результат в hex
+func handleKeywrap(args []string) {
+	opts, err := cli.ParseKeywrapArgs(args)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "error:", err)
+		fmt.Fprintf(os.Stderr, "keywrap error: %v\n", err)
 		os.Exit(1)
 	}
 
-	inputData, err := fs.ReadAll(opts.InputPath)
+	data, err := fs.ReadAll(opts.InputPath)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "error reading input file:", err)
+		fmt.Fprintf(os.Stderr, "error reading input: %v\n", err)
 		os.Exit(1)
 	}
 
-	var key []byte
-	var salt []byte
-
-	if opts.Password != "" {
-		// PBKDF2-режим для шифрования/расшифрования (как у тебя было)
-		if opts.Encrypt {
-			salt, err = crypto.GenerateRandomBytes(16)
-			if err != nil {
-				fmt.Fprintln(os.Stderr, "error generating salt:", err)
-				os.Exit(1)
-			}
-			key = kdf.Key(func() hash.Hash { return myhash.NewSHA256() }, []byte(opts.Password), salt, 4096, 16)
-			fmt.Printf("[INFO] Using PBKDF2 with generated salt: %x\n", salt)
-		} else {
-			if len(inputData) < 16 {
-				fmt.Fprintln(os.Stderr, "error: input file too short to contain salt")
-				os.Exit(1)
-			}
-			salt = inputData[:16]
-			inputData = inputData[16:]
-
-			key = kdf.Key(func() hash.Hash { return myhash.NewSHA256() }, []byte(opts.Password), salt, 4096, 16)
-			fmt.Printf("[INFO] Using PBKDF2 with extracted salt: %x\n", salt)
-		}
-	} else {
-		// raw key
-		var keyHex string
-		if opts.Encrypt && opts.KeyHex == "" {
-			newKey, err := crypto.GenerateRandomBytes(16)
-			if err != nil {
-				fmt.Fprintln(os.Stderr, "error generating key:", err)
-				os.Exit(1)
-			}
-			keyHex = hex.EncodeToString(newKey)
-			fmt.Printf("[INFO] Generated random key: %s\n", keyHex)
-		} else {
-			keyHex = opts.KeyHex
-		}
-
-		key, err = crypto.ParseHexKey(keyHex)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "invalid key:", err)
-			os.Exit(1)
-		}
-	}
-
-	var outputData []byte
-	switch opts.Mode {
-	case "ecb":
-		if opts.Encrypt {
-			outputData, err = crypto.EncryptECB(key, inputData)
-		} else {
-			outputData, err = crypto.DecryptECB(key, inputData)
-		}
-	case "cbc", "cfb", "ofb", "ctr":
-		if opts.Encrypt {
-			outputData, err = crypto.EncryptWithIVMode(opts.Mode, key, inputData)
-		} else {
-			outputData, err = crypto.DecryptWithIVMode(opts.Mode, key, inputData, opts.IVHex, opts.UseIVFlag)
-		}
+	var result []byte
+	switch {
+	case opts.Wrap && opts.Pad:
+		result, err = crypto.WrapKeyWithPadding(opts.KEK, data)
+	case opts.Wrap:
+		result, err = crypto.WrapKey(opts.KEK, data)
+	case opts.Pad:
+		result, err = crypto.UnwrapKeyWithPadding(opts.KEK, data)
 	default:
-		err = fmt.Errorf("unsupported mode: %s", opts.Mode)
+		result, err = crypto.UnwrapKey(opts.KEK, data)
 	}
-
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "crypto error:", err)
+		fmt.Fprintf(os.Stderr, "keywrap error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// если encrypt+password => соль в начало файла
-	if opts.Password != "" && opts.Encrypt {
-		finalOutput := make([]byte, 0, len(salt)+len(outputData))
-		finalOutput = append(finalOutput, salt...)
-		finalOutput = append(finalOutput, outputData...)
-		outputData = finalOutput
-	}
-
-	outputPath := opts.OutputPath
-	if outputPath == "" {
-		if opts.Encrypt {
-			outputPath = fs.DefaultEncryptedName(opts.InputPath)
-		} else {
-			outputPath = fs.DefaultDecryptedName(opts.InputPath)
+	if opts.OutputPath != "" {
+		if err := fs.WriteAll(opts.OutputPath, result); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+			os.Exit(1)
 		}
+		return
 	}
-
-	if err := fs.WriteAll(outputPath, outputData); err != nil {
-		fmt.Fprintln(os.Stderr, "error writing output file:", err)
-		os.Exit(1)
-	}
+	fmt.Println(hex.EncodeToString(result))
 }
 
 func printHelp() {
@@ -255,4 +311,6 @@ func printHelp() {
 	fmt.Println("  cryptocore dgst ...            # Hashing")
 	fmt.Println("  cryptocore hmac ...            # HMAC")
 	fmt.Println("  cryptocore derive ...          # Key derivation (PBKDF2)")
+	fmt.Println("  cryptocore keywrap ...         # AES Key Wrap (RFC 3394/5649)")
+	fmt.Println("  cryptocore verify-chunk ...    # Check one chunk against a sha256-tree digest")
 }
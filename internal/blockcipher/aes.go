@@ -0,0 +1,189 @@
+package blockcipher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+)
+
+// AES по FIPS-197. Состояние — 16 байт по столбцам: state[r+4c] — строка r, столбец c.
+// Раундовые преобразования общие; реализации различаются только S-блоком:
+// aes-go берёт его из таблицы (время доступа зависит от кэша), aes-ct вычисляет
+// без таблиц (см. aes_ct.go).
+
+const blockSize = aes.BlockSize
+
+// sbox, invSbox: таблицы для aes-go, построенные из того же вычисления, что и в aes-ct.
+var sbox, invSbox [256]byte
+
+func init() {
+	for i := 0; i < 256; i++ {
+		sbox[i] = ctSub(byte(i))
+		invSbox[i] = ctInvSub(byte(i))
+	}
+}
+
+func tableSub(b byte) byte { return sbox[b] }
+
+func tableSubBytes(s *[blockSize]byte) {
+	for i := range s {
+		s[i] = sbox[s[i]]
+	}
+}
+
+func tableInvSubBytes(s *[blockSize]byte) {
+	for i := range s {
+		s[i] = invSbox[s[i]]
+	}
+}
+
+type aesCipher struct {
+	rk          [15][blockSize]byte // раундовые ключи 0..nr
+	nr          int
+	sub         func(byte) byte // для расписания ключей
+	subBytes    func(*[blockSize]byte)
+	invSubBytes func(*[blockSize]byte)
+}
+
+func newAESGo(key []byte) (cipher.Block, error) {
+	return newAES(key, &aesCipher{sub: tableSub, subBytes: tableSubBytes, invSubBytes: tableInvSubBytes})
+}
+
+func newAESConstantTime(key []byte) (cipher.Block, error) {
+	return newAES(key, &aesCipher{sub: ctSub, subBytes: ctSubBytes, invSubBytes: ctInvSubBytes})
+}
+
+func newAES(key []byte, c *aesCipher) (*aesCipher, error) {
+	nk := len(key) / 4
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, aes.KeySizeError(len(key))
+	}
+	c.nr = nk + 6
+	c.expandKey(key, nk)
+	return c, nil
+}
+
+// expandKey: KeyExpansion из FIPS-197, 5.2.
+func (c *aesCipher) expandKey(key []byte, nk int) {
+	words := 4 * (c.nr + 1)
+	w := make([][4]byte, words)
+	for i := 0; i < nk; i++ {
+		copy(w[i][:], key[4*i:])
+	}
+	rcon := byte(1)
+	for i := nk; i < words; i++ {
+		t := w[i-1]
+		switch {
+		case i%nk == 0:
+			t = [4]byte{c.sub(t[1]) ^ rcon, c.sub(t[2]), c.sub(t[3]), c.sub(t[0])}
+			rcon = xtime(rcon)
+		case nk > 6 && i%nk == 4:
+			t = [4]byte{c.sub(t[0]), c.sub(t[1]), c.sub(t[2]), c.sub(t[3])}
+		}
+		for j := range t {
+			w[i][j] = w[i-nk][j] ^ t[j]
+		}
+	}
+	for r := 0; r <= c.nr; r++ {
+		for j := 0; j < 4; j++ {
+			copy(c.rk[r][4*j:], w[4*r+j][:])
+		}
+	}
+}
+
+func (c *aesCipher) BlockSize() int { return blockSize }
+
+func (c *aesCipher) Encrypt(dst, src []byte) {
+	if len(src) < blockSize || len(dst) < blockSize {
+		panic("blockcipher: input not full block")
+	}
+	var s [blockSize]byte
+	copy(s[:], src)
+	addRoundKey(&s, &c.rk[0])
+	for r := 1; r < c.nr; r++ {
+		c.subBytes(&s)
+		shiftRows(&s)
+		mixColumns(&s)
+		addRoundKey(&s, &c.rk[r])
+	}
+	c.subBytes(&s)
+	shiftRows(&s)
+	addRoundKey(&s, &c.rk[c.nr])
+	copy(dst, s[:])
+}
+
+// Decrypt: прямой обратный шифр (FIPS-197, 5.3).
+func (c *aesCipher) Decrypt(dst, src []byte) {
+	if len(src) < blockSize || len(dst) < blockSize {
+		panic("blockcipher: input not full block")
+	}
+	var s [blockSize]byte
+	copy(s[:], src)
+	addRoundKey(&s, &c.rk[c.nr])
+	for r := c.nr - 1; r > 0; r-- {
+		invShiftRows(&s)
+		c.invSubBytes(&s)
+		addRoundKey(&s, &c.rk[r])
+		invMixColumns(&s)
+	}
+	invShiftRows(&s)
+	c.invSubBytes(&s)
+	addRoundKey(&s, &c.rk[0])
+	copy(dst, s[:])
+}
+
+func addRoundKey(s, k *[blockSize]byte) {
+	for i := range s {
+		s[i] ^= k[i]
+	}
+}
+
+// shiftRows: строка r циклически сдвигается влево на r позиций.
+func shiftRows(s *[blockSize]byte) {
+	t := *s
+	for r := 1; r < 4; r++ {
+		for col := 0; col < 4; col++ {
+			s[r+4*col] = t[r+4*((col+r)%4)]
+		}
+	}
+}
+
+func invShiftRows(s *[blockSize]byte) {
+	t := *s
+	for r := 1; r < 4; r++ {
+		for col := 0; col < 4; col++ {
+			s[r+4*((col+r)%4)] = t[r+4*col]
+		}
+	}
+}
+
+func mixColumns(s *[blockSize]byte) {
+	for col := 0; col < 4; col++ {
+		a0, a1, a2, a3 := s[4*col], s[4*col+1], s[4*col+2], s[4*col+3]
+		s[4*col] = xtime(a0) ^ xtime(a1) ^ a1 ^ a2 ^ a3
+		s[4*col+1] = a0 ^ xtime(a1) ^ xtime(a2) ^ a2 ^ a3
+		s[4*col+2] = a0 ^ a1 ^ xtime(a2) ^ xtime(a3) ^ a3
+		s[4*col+3] = xtime(a0) ^ a0 ^ a1 ^ a2 ^ xtime(a3)
+	}
+}
+
+// invMixColumns: коэффициенты 0e 0b 0d 09 через xtime (9 = 8+1, 11 = 8+2+1, 13 = 8+4+1, 14 = 8+4+2).
+func invMixColumns(s *[blockSize]byte) {
+	for col := 0; col < 4; col++ {
+		var x9, x11, x13, x14 [4]byte
+		for i := 0; i < 4; i++ {
+			a := s[4*col+i]
+			a2 := xtime(a)
+			a4 := xtime(a2)
+			a8 := xtime(a4)
+			x9[i] = a8 ^ a
+			x11[i] = a8 ^ a2 ^ a
+			x13[i] = a8 ^ a4 ^ a
+			x14[i] = a8 ^ a4 ^ a2
+		}
+		for i := 0; i < 4; i++ {
+			s[4*col+i] = x14[i] ^ x11[(i+1)%4] ^ x13[(i+2)%4] ^ x9[(i+3)%4]
+		}
+	}
+}
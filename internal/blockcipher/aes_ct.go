@@ -0,0 +1,111 @@
+package blockcipher
+
+import "encoding/binary"
+
+// S-блок AES без таблиц: обращение в GF(2^8) как x^254 и аффинное преобразование.
+// Умножение выполняется по маскам, без ветвлений и обращений к памяти по индексу
+// из данных, поэтому время не зависит ни от ключа, ни от открытого текста.
+// Цена — около сотни операций на байт; aes-ct предназначен для проверки и обучения,
+// а не для скорости.
+
+// xtime: умножение на x по модулю x^8 + x^4 + x^3 + x + 1.
+func xtime(b byte) byte {
+	return b<<1 ^ 0x1b&-(b>>7)
+}
+
+// gmul: произведение в GF(2^8), всегда 8 итераций.
+func gmul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= a & -(b & 1)
+		a = xtime(a)
+		b >>= 1
+	}
+	return p
+}
+
+// gfInverse: x^254 = x^-1 (и 0 -> 0) цепочкой 240 + 12 + 2.
+func gfInverse(x byte) byte {
+	x2 := gmul(x, x)
+	x3 := gmul(x2, x)
+	x6 := gmul(x3, x3)
+	x12 := gmul(x6, x6)
+	x15 := gmul(x12, x3)
+	x30 := gmul(x15, x15)
+	x60 := gmul(x30, x30)
+	x120 := gmul(x60, x60)
+	x240 := gmul(x120, x120)
+	return gmul(gmul(x240, x12), x2)
+}
+
+func rotl8(b byte, n uint) byte {
+	return b<<n | b>>(8-n)
+}
+
+// ctSub: SubBytes (FIPS-197, 5.1.1).
+func ctSub(b byte) byte {
+	x := gfInverse(b)
+	return x ^ rotl8(x, 1) ^ rotl8(x, 2) ^ rotl8(x, 3) ^ rotl8(x, 4) ^ 0x63
+}
+
+// ctInvSub: обратное аффинное преобразование, затем обращение.
+func ctInvSub(b byte) byte {
+	return gfInverse(rotl8(b, 1) ^ rotl8(b, 3) ^ rotl8(b, 6) ^ 0x05)
+}
+
+// SubBytes для всего состояния: те же вычисления над 8 байтами в uint64 (SWAR),
+// каждый байт — независимая «дорожка».
+const (
+	lanes01 = 0x0101010101010101
+	lanes7f = 0x7f7f7f7f7f7f7f7f
+)
+
+func xtime64(a uint64) uint64 {
+	return (a&lanes7f)<<1 ^ (a>>7&lanes01)*0x1b
+}
+
+func gmul64(a, b uint64) uint64 {
+	var p uint64
+	for i := 0; i < 8; i++ {
+		p ^= a & ((b & lanes01) * 0xff)
+		a = xtime64(a)
+		b >>= 1
+	}
+	return p
+}
+
+func gfInverse64(x uint64) uint64 {
+	x2 := gmul64(x, x)
+	x3 := gmul64(x2, x)
+	x6 := gmul64(x3, x3)
+	x12 := gmul64(x6, x6)
+	x15 := gmul64(x12, x3)
+	x30 := gmul64(x15, x15)
+	x60 := gmul64(x30, x30)
+	x120 := gmul64(x60, x60)
+	x240 := gmul64(x120, x120)
+	return gmul64(gmul64(x240, x12), x2)
+}
+
+// rotl64: rotl8 в каждой дорожке.
+func rotl64(x uint64, n uint) uint64 {
+	low := uint64(1)<<n - 1
+	low *= lanes01
+	return x<<n&^low | x>>(8-n)&low
+}
+
+func ctSubBytes(s *[blockSize]byte) {
+	for h := 0; h < blockSize; h += 8 {
+		x := gfInverse64(binary.LittleEndian.Uint64(s[h:]))
+		x ^= rotl64(x, 1) ^ rotl64(x, 2) ^ rotl64(x, 3) ^ rotl64(x, 4) ^ 0x63*lanes01
+		binary.LittleEndian.PutUint64(s[h:], x)
+	}
+}
+
+func ctInvSubBytes(s *[blockSize]byte) {
+	for h := 0; h < blockSize; h += 8 {
+		x := binary.LittleEndian.Uint64(s[h:])
+		x = rotl64(x, 1) ^ rotl64(x, 3) ^ rotl64(x, 6) ^ 0x05*lanes01
+		binary.LittleEndian.PutUint64(s[h:], gfInverse64(x))
+	}
+}
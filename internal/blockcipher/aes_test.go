@@ -0,0 +1,110 @@
+package blockcipher
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+)
+
+func TestAES_FIPS197(t *testing.T) {
+	// FIPS-197, приложение B и C.1-C.3
+	vectors := []struct {
+		key, plaintext, ciphertext string
+	}{
+		{"2b7e151628aed2a6abf7158809cf4f3c", "3243f6a8885a308d313198a2e0370734", "3925841d02dc09fbdc118597196a0b32"},
+		{"000102030405060708090a0b0c0d0e0f", "00112233445566778899aabbccddeeff", "69c4e0d86a7b0430d8cdb78070b4c55a"},
+		{"000102030405060708090a0b0c0d0e0f1011121314151617", "00112233445566778899aabbccddeeff", "dda97ca4864cdfe06eaf70a0ec0d7191"},
+		{"000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", "00112233445566778899aabbccddeeff", "8ea2b7ca516745bfeafc49904b496089"},
+	}
+
+	for _, impl := range AESImplementations() {
+		for _, v := range vectors {
+			key, _ := hex.DecodeString(v.key)
+			pt, _ := hex.DecodeString(v.plaintext)
+			block, err := New(impl, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := make([]byte, 16)
+			block.Encrypt(out, pt)
+			if got := hex.EncodeToString(out); got != v.ciphertext {
+				t.Fatalf("%s/%d: encrypt got %s, want %s", impl, len(key)*8, got, v.ciphertext)
+			}
+			block.Decrypt(out, out)
+			if !bytes.Equal(out, pt) {
+				t.Fatalf("%s/%d: decrypt mismatch", impl, len(key)*8)
+			}
+		}
+	}
+}
+
+func TestAES_MatchesStdlib(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, impl := range []string{AESGo, AESConstantTime} {
+		for _, size := range []int{16, 24, 32} {
+			for i := 0; i < 50; i++ {
+				key := make([]byte, size)
+				src := make([]byte, 16)
+				rng.Read(key)
+				rng.Read(src)
+
+				std, _ := aes.NewCipher(key)
+				own, err := New(impl, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, got := make([]byte, 16), make([]byte, 16)
+				std.Encrypt(want, src)
+				own.Encrypt(got, src)
+				if !bytes.Equal(got, want) {
+					t.Fatalf("%s/%d: encrypt differs from crypto/aes for key %x", impl, size*8, key)
+				}
+				std.Decrypt(want, src)
+				own.Decrypt(got, src)
+				if !bytes.Equal(got, want) {
+					t.Fatalf("%s/%d: decrypt differs from crypto/aes for key %x", impl, size*8, key)
+				}
+			}
+		}
+	}
+}
+
+func TestAES_SBox(t *testing.T) {
+	// FIPS-197, рис. 7: S(0x00) = 0x63, S(0x53) = 0xed
+	if ctSub(0x00) != 0x63 || ctSub(0x53) != 0xed {
+		t.Fatalf("sbox: S(00)=%02x S(53)=%02x", ctSub(0x00), ctSub(0x53))
+	}
+	for i := 0; i < 256; i++ {
+		if ctInvSub(ctSub(byte(i))) != byte(i) {
+			t.Fatalf("inverse sbox mismatch at %02x", i)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	if _, err := New("no-such-cipher", make([]byte, 16)); err == nil {
+		t.Fatal("expected error for unknown cipher")
+	}
+	if _, err := New(AESConstantTime, make([]byte, 20)); err == nil {
+		t.Fatal("expected error for 20-byte AES key")
+	}
+	if err := SetAESImplementation("des"); err == nil {
+		t.Fatal("expected error for unknown AES implementation")
+	}
+}
+
+func BenchmarkAES(b *testing.B) {
+	key := make([]byte, 16)
+	buf := make([]byte, 16)
+	for _, impl := range AESImplementations() {
+		block, _ := New(impl, key)
+		b.Run(impl, func(b *testing.B) {
+			b.SetBytes(16)
+			for i := 0; i < b.N; i++ {
+				block.Encrypt(buf, buf)
+			}
+		})
+	}
+}
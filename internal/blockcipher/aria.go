@@ -0,0 +1,194 @@
+package blockcipher
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+)
+
+// ARIA (RFC 5794): SPN из 12/14/16 раундов с S-блоками SB1..SB4 и инволютивным
+// диффузионным слоем A. SB1 и SB3 — S-блок AES и обратный к нему.
+
+type ariaCipher struct {
+	rounds   int
+	enc, dec [17][blockSize]byte
+}
+
+var ariaC = [3]uint128{
+	{0x517cc1b727220a94, 0xfe13abe8fa9a6ee0},
+	{0x6db14acc9e21c820, 0xff28b1d5ef5de2b0},
+	{0xdb92371d2126e970, 0x0324977504e8c90e},
+}
+
+var ariaSB4 [256]byte
+
+func init() {
+	for i := 0; i < 256; i++ {
+		ariaSB4[ariaSB2[i]] = byte(i)
+	}
+}
+
+func newARIA(key []byte) (cipher.Block, error) {
+	c := &ariaCipher{}
+	var ck [3]uint128
+	switch len(key) {
+	case 16:
+		c.rounds = 12
+		ck = [3]uint128{ariaC[0], ariaC[1], ariaC[2]}
+	case 24:
+		c.rounds = 14
+		ck = [3]uint128{ariaC[1], ariaC[2], ariaC[0]}
+	case 32:
+		c.rounds = 16
+		ck = [3]uint128{ariaC[2], ariaC[0], ariaC[1]}
+	default:
+		return nil, keySizeError("aria", len(key))
+	}
+	var kr [blockSize]byte
+	copy(kr[:], key[16:])
+	kl := loadUint128(key)
+
+	w0 := kl
+	w1 := ariaFO(w0, ck[0]).xor(loadUint128(kr[:]))
+	w2 := ariaFE(w1, ck[1]).xor(w0)
+	w3 := ariaFO(w2, ck[2]).xor(w1)
+
+	// RFC 5794, 2.2: ek1..ek17 — сдвиги W0..W3 на 19, 31, 61, 31 и 19 бит
+	// (первые два — вправо, остальные — влево).
+	w := [4]uint128{w0, w1, w2, w3}
+	rot := [5]uint{128 - 19, 128 - 31, 61, 31, 19}
+	var ek [17]uint128
+	for i := range ek {
+		g, j := i/4, i%4
+		ek[i] = w[j].xor(w[(j+1)%4].rotl(rot[g]))
+	}
+
+	for i := 0; i <= c.rounds; i++ {
+		ek[i].store(c.enc[i][:])
+	}
+	// Ключи расшифрования: крайние без изменений, средние пропущены через A.
+	c.dec[0] = c.enc[c.rounds]
+	c.dec[c.rounds] = c.enc[0]
+	for i := 1; i < c.rounds; i++ {
+		c.dec[i] = c.enc[c.rounds-i]
+		ariaA(&c.dec[i])
+	}
+	return c, nil
+}
+
+func loadUint128(b []byte) uint128 {
+	return uint128{binary.BigEndian.Uint64(b), binary.BigEndian.Uint64(b[8:])}
+}
+
+func (x uint128) store(b []byte) {
+	binary.BigEndian.PutUint64(b, x.hi)
+	binary.BigEndian.PutUint64(b[8:], x.lo)
+}
+
+func (x uint128) xor(y uint128) uint128 {
+	return uint128{x.hi ^ y.hi, x.lo ^ y.lo}
+}
+
+func ariaFO(d, rk uint128) uint128 {
+	var s [blockSize]byte
+	d.xor(rk).store(s[:])
+	ariaSL1(&s)
+	ariaA(&s)
+	return loadUint128(s[:])
+}
+
+func ariaFE(d, rk uint128) uint128 {
+	var s [blockSize]byte
+	d.xor(rk).store(s[:])
+	ariaSL2(&s)
+	ariaA(&s)
+	return loadUint128(s[:])
+}
+
+// ariaSL1: SB1, SB2, SB3, SB4 по кругу.
+func ariaSL1(s *[blockSize]byte) {
+	for i := 0; i < blockSize; i += 4 {
+		s[i] = sbox[s[i]]
+		s[i+1] = ariaSB2[s[i+1]]
+		s[i+2] = invSbox[s[i+2]]
+		s[i+3] = ariaSB4[s[i+3]]
+	}
+}
+
+// ariaSL2: SB3, SB4, SB1, SB2 — обратный к SL1.
+func ariaSL2(s *[blockSize]byte) {
+	for i := 0; i < blockSize; i += 4 {
+		s[i] = invSbox[s[i]]
+		s[i+1] = ariaSB4[s[i+1]]
+		s[i+2] = sbox[s[i+2]]
+		s[i+3] = ariaSB2[s[i+3]]
+	}
+}
+
+// ariaA: диффузионный слой (RFC 5794, 2.4.3).
+func ariaA(s *[blockSize]byte) {
+	x := *s
+	s[0] = x[3] ^ x[4] ^ x[6] ^ x[8] ^ x[9] ^ x[13] ^ x[14]
+	s[1] = x[2] ^ x[5] ^ x[7] ^ x[8] ^ x[9] ^ x[12] ^ x[15]
+	s[2] = x[1] ^ x[4] ^ x[6] ^ x[10] ^ x[11] ^ x[12] ^ x[15]
+	s[3] = x[0] ^ x[5] ^ x[7] ^ x[10] ^ x[11] ^ x[13] ^ x[14]
+	s[4] = x[0] ^ x[2] ^ x[5] ^ x[8] ^ x[11] ^ x[14] ^ x[15]
+	s[5] = x[1] ^ x[3] ^ x[4] ^ x[9] ^ x[10] ^ x[14] ^ x[15]
+	s[6] = x[0] ^ x[2] ^ x[7] ^ x[9] ^ x[10] ^ x[12] ^ x[13]
+	s[7] = x[1] ^ x[3] ^ x[6] ^ x[8] ^ x[11] ^ x[12] ^ x[13]
+	s[8] = x[0] ^ x[1] ^ x[4] ^ x[7] ^ x[10] ^ x[13] ^ x[15]
+	s[9] = x[0] ^ x[1] ^ x[5] ^ x[6] ^ x[11] ^ x[12] ^ x[14]
+	s[10] = x[2] ^ x[3] ^ x[5] ^ x[6] ^ x[8] ^ x[13] ^ x[15]
+	s[11] = x[2] ^ x[3] ^ x[4] ^ x[7] ^ x[9] ^ x[12] ^ x[14]
+	s[12] = x[1] ^ x[2] ^ x[6] ^ x[7] ^ x[9] ^ x[11] ^ x[12]
+	s[13] = x[0] ^ x[3] ^ x[6] ^ x[7] ^ x[8] ^ x[10] ^ x[13]
+	s[14] = x[0] ^ x[3] ^ x[4] ^ x[5] ^ x[9] ^ x[11] ^ x[14]
+	s[15] = x[1] ^ x[2] ^ x[4] ^ x[5] ^ x[8] ^ x[10] ^ x[15]
+}
+
+func (c *ariaCipher) BlockSize() int { return blockSize }
+
+func (c *ariaCipher) Encrypt(dst, src []byte) { c.crypt(dst, src, &c.enc) }
+
+func (c *ariaCipher) Decrypt(dst, src []byte) { c.crypt(dst, src, &c.dec) }
+
+// crypt: нечётные раунды — FO, чётные — FE; последний раунд вместо A добавляет ключ.
+func (c *ariaCipher) crypt(dst, src []byte, rk *[17][blockSize]byte) {
+	if len(src) < blockSize || len(dst) < blockSize {
+		panic("blockcipher: input not full block")
+	}
+	var s [blockSize]byte
+	copy(s[:], src)
+	for r := 0; r < c.rounds-1; r++ {
+		addRoundKey(&s, &rk[r])
+		if r%2 == 0 {
+			ariaSL1(&s)
+		} else {
+			ariaSL2(&s)
+		}
+		ariaA(&s)
+	}
+	addRoundKey(&s, &rk[c.rounds-1])
+	ariaSL2(&s)
+	addRoundKey(&s, &rk[c.rounds])
+	copy(dst, s[:])
+}
+
+// ariaSB2: S-блок SB2 из RFC 5794, 2.4.2.
+var ariaSB2 = [256]byte{
+	0xe2, 0x4e, 0x54, 0xfc, 0x94, 0xc2, 0x4a, 0xcc, 0x62, 0x0d, 0x6a, 0x46, 0x3c, 0x4d, 0x8b, 0xd1,
+	0x5e, 0xfa, 0x64, 0xcb, 0xb4, 0x97, 0xbe, 0x2b, 0xbc, 0x77, 0x2e, 0x03, 0xd3, 0x19, 0x59, 0xc1,
+	0x1d, 0x06, 0x41, 0x6b, 0x55, 0xf0, 0x99, 0x69, 0xea, 0x9c, 0x18, 0xae, 0x63, 0xdf, 0xe7, 0xbb,
+	0x00, 0x73, 0x66, 0xfb, 0x96, 0x4c, 0x85, 0xe4, 0x3a, 0x09, 0x45, 0xaa, 0x0f, 0xee, 0x10, 0xeb,
+	0x2d, 0x7f, 0xf4, 0x29, 0xac, 0xcf, 0xad, 0x91, 0x8d, 0x78, 0xc8, 0x95, 0xf9, 0x2f, 0xce, 0xcd,
+	0x08, 0x7a, 0x88, 0x38, 0x5c, 0x83, 0x2a, 0x28, 0x47, 0xdb, 0xb8, 0xc7, 0x93, 0xa4, 0x12, 0x53,
+	0xff, 0x87, 0x0e, 0x31, 0x36, 0x21, 0x58, 0x48, 0x01, 0x8e, 0x37, 0x74, 0x32, 0xca, 0xe9, 0xb1,
+	0xb7, 0xab, 0x0c, 0xd7, 0xc4, 0x56, 0x42, 0x26, 0x07, 0x98, 0x60, 0xd9, 0xb6, 0xb9, 0x11, 0x40,
+	0xec, 0x20, 0x8c, 0xbd, 0xa0, 0xc9, 0x84, 0x04, 0x49, 0x23, 0xf1, 0x4f, 0x50, 0x1f, 0x13, 0xdc,
+	0xd8, 0xc0, 0x9e, 0x57, 0xe3, 0xc3, 0x7b, 0x65, 0x3b, 0x02, 0x8f, 0x3e, 0xe8, 0x25, 0x92, 0xe5,
+	0x15, 0xdd, 0xfd, 0x17, 0xa9, 0xbf, 0xd4, 0x9a, 0x7e, 0xc5, 0x39, 0x67, 0xfe, 0x76, 0x9d, 0x43,
+	0xa7, 0xe1, 0xd0, 0xf5, 0x68, 0xf2, 0x1b, 0x34, 0x70, 0x05, 0xa3, 0x8a, 0xd5, 0x79, 0x86, 0xa8,
+	0x30, 0xc6, 0x51, 0x4b, 0x1e, 0xa6, 0x27, 0xf6, 0x35, 0xd2, 0x6e, 0x24, 0x16, 0x82, 0x5f, 0xda,
+	0xe6, 0x75, 0xa2, 0xef, 0x2c, 0xb2, 0x1c, 0x9f, 0x5d, 0x6f, 0x80, 0x0a, 0x72, 0x44, 0x9b, 0x6c,
+	0x90, 0x0b, 0x5b, 0x33, 0x7d, 0x5a, 0x52, 0xf3, 0x61, 0xa1, 0xf7, 0xb0, 0xd6, 0x3f, 0x7c, 0x6d,
+	0xed, 0x14, 0xe0, 0xa5, 0x3d, 0x22, 0xb3, 0xf8, 0x89, 0xde, 0x71, 0x1a, 0xaf, 0xba, 0xb5, 0x81,
+}
@@ -0,0 +1,217 @@
+package blockcipher
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math/bits"
+)
+
+// Camellia (RFC 3713): сеть Фейстеля над двумя 64-битными половинами, 18 раундов для
+// 128-битного ключа и 24 для 192/256, с функциями FL/FLINV через каждые 6 раундов.
+
+type camelliaKeys struct {
+	kw [4]uint64
+	k  [24]uint64
+	ke [6]uint64
+}
+
+type camelliaCipher struct {
+	rounds   int
+	enc, dec camelliaKeys
+}
+
+var camelliaSigma = [6]uint64{
+	0xa09e667f3bcc908b, 0xb67ae8584caa73b2, 0xc6ef372fe94f82be,
+	0x54ff53a5f1d36f1c, 0x10e527fade682d1d, 0xb05688c2b3e6c1fd,
+}
+
+// SBOX2..SBOX4 выводятся из SBOX1 поворотами (RFC 3713, 2.4.4).
+var camelliaSbox2, camelliaSbox3, camelliaSbox4 [256]byte
+
+func init() {
+	for i := 0; i < 256; i++ {
+		s := camelliaSbox1[i]
+		camelliaSbox2[i] = rotl8(s, 1)
+		camelliaSbox3[i] = rotl8(s, 7)
+		camelliaSbox4[i] = camelliaSbox1[rotl8(byte(i), 1)]
+	}
+}
+
+// uint128 — пара (старшие, младшие) 64 бита для расписания ключей.
+type uint128 struct{ hi, lo uint64 }
+
+func (x uint128) rotl(n uint) uint128 {
+	if n >= 64 {
+		x.hi, x.lo = x.lo, x.hi
+		n -= 64
+	}
+	if n == 0 {
+		return x
+	}
+	return uint128{x.hi<<n | x.lo>>(64-n), x.lo<<n | x.hi>>(64-n)}
+}
+
+func newCamellia(key []byte) (cipher.Block, error) {
+	var kl, kr uint128
+	switch len(key) {
+	case 16:
+	case 24:
+		kr.hi = binary.BigEndian.Uint64(key[16:])
+		kr.lo = ^kr.hi
+	case 32:
+		kr.hi = binary.BigEndian.Uint64(key[16:])
+		kr.lo = binary.BigEndian.Uint64(key[24:])
+	default:
+		return nil, keySizeError("camellia", len(key))
+	}
+	kl.hi = binary.BigEndian.Uint64(key[0:])
+	kl.lo = binary.BigEndian.Uint64(key[8:])
+
+	d1, d2 := kl.hi^kr.hi, kl.lo^kr.lo
+	d2 ^= camelliaF(d1, camelliaSigma[0])
+	d1 ^= camelliaF(d2, camelliaSigma[1])
+	d1 ^= kl.hi
+	d2 ^= kl.lo
+	d2 ^= camelliaF(d1, camelliaSigma[2])
+	d1 ^= camelliaF(d2, camelliaSigma[3])
+	ka := uint128{d1, d2}
+
+	c := &camelliaCipher{}
+	e := &c.enc
+	if len(key) == 16 {
+		c.rounds = 18
+		// RFC 3713, 2.2: подключи для 128-битного ключа.
+		e.kw[0], e.kw[1] = kl.hi, kl.lo
+		e.k[0], e.k[1] = ka.hi, ka.lo
+		e.k[2], e.k[3] = kl.rotl(15).hi, kl.rotl(15).lo
+		e.k[4], e.k[5] = ka.rotl(15).hi, ka.rotl(15).lo
+		e.ke[0], e.ke[1] = ka.rotl(30).hi, ka.rotl(30).lo
+		e.k[6], e.k[7] = kl.rotl(45).hi, kl.rotl(45).lo
+		e.k[8], e.k[9] = ka.rotl(45).hi, kl.rotl(60).lo
+		e.k[10], e.k[11] = ka.rotl(60).hi, ka.rotl(60).lo
+		e.ke[2], e.ke[3] = kl.rotl(77).hi, kl.rotl(77).lo
+		e.k[12], e.k[13] = kl.rotl(94).hi, kl.rotl(94).lo
+		e.k[14], e.k[15] = ka.rotl(94).hi, ka.rotl(94).lo
+		e.k[16], e.k[17] = kl.rotl(111).hi, kl.rotl(111).lo
+		e.kw[2], e.kw[3] = ka.rotl(111).hi, ka.rotl(111).lo
+	} else {
+		c.rounds = 24
+		d1, d2 = ka.hi^kr.hi, ka.lo^kr.lo
+		d2 ^= camelliaF(d1, camelliaSigma[4])
+		d1 ^= camelliaF(d2, camelliaSigma[5])
+		kb := uint128{d1, d2}
+
+		// RFC 3713, 2.2: подключи для 192- и 256-битного ключа.
+		e.kw[0], e.kw[1] = kl.hi, kl.lo
+		e.k[0], e.k[1] = kb.hi, kb.lo
+		e.k[2], e.k[3] = kr.rotl(15).hi, kr.rotl(15).lo
+		e.k[4], e.k[5] = ka.rotl(15).hi, ka.rotl(15).lo
+		e.ke[0], e.ke[1] = kr.rotl(30).hi, kr.rotl(30).lo
+		e.k[6], e.k[7] = kb.rotl(30).hi, kb.rotl(30).lo
+		e.k[8], e.k[9] = kl.rotl(45).hi, kl.rotl(45).lo
+		e.k[10], e.k[11] = ka.rotl(45).hi, ka.rotl(45).lo
+		e.ke[2], e.ke[3] = kl.rotl(60).hi, kl.rotl(60).lo
+		e.k[12], e.k[13] = kr.rotl(60).hi, kr.rotl(60).lo
+		e.k[14], e.k[15] = kb.rotl(60).hi, kb.rotl(60).lo
+		e.k[16], e.k[17] = kl.rotl(77).hi, kl.rotl(77).lo
+		e.ke[4], e.ke[5] = ka.rotl(77).hi, ka.rotl(77).lo
+		e.k[18], e.k[19] = kr.rotl(94).hi, kr.rotl(94).lo
+		e.k[20], e.k[21] = ka.rotl(94).hi, ka.rotl(94).lo
+		e.k[22], e.k[23] = kl.rotl(111).hi, kl.rotl(111).lo
+		e.kw[2], e.kw[3] = kb.rotl(111).hi, kb.rotl(111).lo
+	}
+
+	// Расшифрование — та же схема с подключами в обратном порядке.
+	d := &c.dec
+	d.kw = [4]uint64{e.kw[2], e.kw[3], e.kw[0], e.kw[1]}
+	for i := 0; i < c.rounds; i++ {
+		d.k[i] = e.k[c.rounds-1-i]
+	}
+	nke := 2 * (c.rounds/6 - 1)
+	for i := 0; i < nke; i++ {
+		d.ke[i] = e.ke[nke-1-i]
+	}
+	return c, nil
+}
+
+// camelliaF: F-функция (RFC 3713, 2.4.1).
+func camelliaF(in, ke uint64) uint64 {
+	x := in ^ ke
+	t1 := uint64(camelliaSbox1[x>>56])
+	t2 := uint64(camelliaSbox2[x>>48&0xff])
+	t3 := uint64(camelliaSbox3[x>>40&0xff])
+	t4 := uint64(camelliaSbox4[x>>32&0xff])
+	t5 := uint64(camelliaSbox2[x>>24&0xff])
+	t6 := uint64(camelliaSbox3[x>>16&0xff])
+	t7 := uint64(camelliaSbox4[x>>8&0xff])
+	t8 := uint64(camelliaSbox1[x&0xff])
+	y1 := t1 ^ t3 ^ t4 ^ t6 ^ t7 ^ t8
+	y2 := t1 ^ t2 ^ t4 ^ t5 ^ t7 ^ t8
+	y3 := t1 ^ t2 ^ t3 ^ t5 ^ t6 ^ t8
+	y4 := t2 ^ t3 ^ t4 ^ t5 ^ t6 ^ t7
+	y5 := t1 ^ t2 ^ t6 ^ t7 ^ t8
+	y6 := t2 ^ t3 ^ t5 ^ t7 ^ t8
+	y7 := t3 ^ t4 ^ t5 ^ t6 ^ t8
+	y8 := t1 ^ t4 ^ t5 ^ t6 ^ t7
+	return y1<<56 | y2<<48 | y3<<40 | y4<<32 | y5<<24 | y6<<16 | y7<<8 | y8
+}
+
+func camelliaFL(in, ke uint64) uint64 {
+	x1, x2 := uint32(in>>32), uint32(in)
+	k1, k2 := uint32(ke>>32), uint32(ke)
+	x2 ^= bits.RotateLeft32(x1&k1, 1)
+	x1 ^= x2 | k2
+	return uint64(x1)<<32 | uint64(x2)
+}
+
+func camelliaFLInv(in, ke uint64) uint64 {
+	y1, y2 := uint32(in>>32), uint32(in)
+	k1, k2 := uint32(ke>>32), uint32(ke)
+	y1 ^= y2 | k2
+	y2 ^= bits.RotateLeft32(y1&k1, 1)
+	return uint64(y1)<<32 | uint64(y2)
+}
+
+func (c *camelliaCipher) BlockSize() int { return blockSize }
+
+func (c *camelliaCipher) Encrypt(dst, src []byte) { c.crypt(dst, src, &c.enc) }
+
+func (c *camelliaCipher) Decrypt(dst, src []byte) { c.crypt(dst, src, &c.dec) }
+
+func (c *camelliaCipher) crypt(dst, src []byte, k *camelliaKeys) {
+	if len(src) < blockSize || len(dst) < blockSize {
+		panic("blockcipher: input not full block")
+	}
+	d1 := binary.BigEndian.Uint64(src[0:]) ^ k.kw[0]
+	d2 := binary.BigEndian.Uint64(src[8:]) ^ k.kw[1]
+	for i := 0; i < c.rounds; i += 2 {
+		if i > 0 && i%6 == 0 {
+			d1 = camelliaFL(d1, k.ke[i/3-2])
+			d2 = camelliaFLInv(d2, k.ke[i/3-1])
+		}
+		d2 ^= camelliaF(d1, k.k[i])
+		d1 ^= camelliaF(d2, k.k[i+1])
+	}
+	binary.BigEndian.PutUint64(dst[0:], d2^k.kw[2])
+	binary.BigEndian.PutUint64(dst[8:], d1^k.kw[3])
+}
+
+// camelliaSbox1: SBOX1 из RFC 3713, 2.4.4.
+var camelliaSbox1 = [256]byte{
+	0x70, 0x82, 0x2c, 0xec, 0xb3, 0x27, 0xc0, 0xe5, 0xe4, 0x85, 0x57, 0x35, 0xea, 0x0c, 0xae, 0x41,
+	0x23, 0xef, 0x6b, 0x93, 0x45, 0x19, 0xa5, 0x21, 0xed, 0x0e, 0x4f, 0x4e, 0x1d, 0x65, 0x92, 0xbd,
+	0x86, 0xb8, 0xaf, 0x8f, 0x7c, 0xeb, 0x1f, 0xce, 0x3e, 0x30, 0xdc, 0x5f, 0x5e, 0xc5, 0x0b, 0x1a,
+	0xa6, 0xe1, 0x39, 0xca, 0xd5, 0x47, 0x5d, 0x3d, 0xd9, 0x01, 0x5a, 0xd6, 0x51, 0x56, 0x6c, 0x4d,
+	0x8b, 0x0d, 0x9a, 0x66, 0xfb, 0xcc, 0xb0, 0x2d, 0x74, 0x12, 0x2b, 0x20, 0xf0, 0xb1, 0x84, 0x99,
+	0xdf, 0x4c, 0xcb, 0xc2, 0x34, 0x7e, 0x76, 0x05, 0x6d, 0xb7, 0xa9, 0x31, 0xd1, 0x17, 0x04, 0xd7,
+	0x14, 0x58, 0x3a, 0x61, 0xde, 0x1b, 0x11, 0x1c, 0x32, 0x0f, 0x9c, 0x16, 0x53, 0x18, 0xf2, 0x22,
+	0xfe, 0x44, 0xcf, 0xb2, 0xc3, 0xb5, 0x7a, 0x91, 0x24, 0x08, 0xe8, 0xa8, 0x60, 0xfc, 0x69, 0x50,
+	0xaa, 0xd0, 0xa0, 0x7d, 0xa1, 0x89, 0x62, 0x97, 0x54, 0x5b, 0x1e, 0x95, 0xe0, 0xff, 0x64, 0xd2,
+	0x10, 0xc4, 0x00, 0x48, 0xa3, 0xf7, 0x75, 0xdb, 0x8a, 0x03, 0xe6, 0xda, 0x09, 0x3f, 0xdd, 0x94,
+	0x87, 0x5c, 0x83, 0x02, 0xcd, 0x4a, 0x90, 0x33, 0x73, 0x67, 0xf6, 0xf3, 0x9d, 0x7f, 0xbf, 0xe2,
+	0x52, 0x9b, 0xd8, 0x26, 0xc8, 0x37, 0xc6, 0x3b, 0x81, 0x96, 0x6f, 0x4b, 0x13, 0xbe, 0x63, 0x2e,
+	0xe9, 0x79, 0xa7, 0x8c, 0x9f, 0x6e, 0xbc, 0x8e, 0x29, 0xf5, 0xf9, 0xb6, 0x2f, 0xfd, 0xb4, 0x59,
+	0x78, 0x98, 0x06, 0x6a, 0xe7, 0x46, 0x71, 0xba, 0xd4, 0x25, 0xab, 0x42, 0x88, 0xa2, 0x8d, 0xfa,
+	0x72, 0x07, 0xb9, 0x55, 0xf8, 0xee, 0xac, 0x0a, 0x36, 0x49, 0x2a, 0x68, 0x3c, 0x38, 0xf1, 0xa4,
+	0x40, 0x28, 0xd3, 0x7b, 0xbb, 0xc9, 0x43, 0xc1, 0x15, 0xe3, 0xad, 0xf4, 0x77, 0xc7, 0x80, 0x9e,
+}
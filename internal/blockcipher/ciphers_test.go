@@ -0,0 +1,66 @@
+package blockcipher
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestCiphers_KnownAnswers(t *testing.T) {
+	vectors := []struct {
+		name, key, plaintext, ciphertext string
+	}{
+		// RFC 3713, приложение A
+		{"camellia", "0123456789abcdeffedcba9876543210", "0123456789abcdeffedcba9876543210", "67673138549669730857065648eabe43"},
+		{"camellia", "0123456789abcdeffedcba98765432100011223344556677", "0123456789abcdeffedcba9876543210", "b4993401b3e996f84ee5cee7d79b09b9"},
+		{"camellia", "0123456789abcdeffedcba987654321000112233445566778899aabbccddeeff", "0123456789abcdeffedcba9876543210", "9acc237dff16d76c20ef7c919e3a7509"},
+		// RFC 5794, приложение A
+		{"aria", "000102030405060708090a0b0c0d0e0f", "00112233445566778899aabbccddeeff", "d718fbd6ab644c739da95f3be6451778"},
+		{"aria", "000102030405060708090a0b0c0d0e0f1011121314151617", "00112233445566778899aabbccddeeff", "26449c1805dbe7aa25a468ce263a9e79"},
+		{"aria", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", "00112233445566778899aabbccddeeff", "f92bd7c79fb72e2f2b8f80c1972d24fc"},
+		// GB/T 32907-2016, приложение A
+		{"sm4", "0123456789abcdeffedcba9876543210", "0123456789abcdeffedcba9876543210", "681edf34d206965e86b3e94f536e4246"},
+		// NESSIE, Set 1, vector 0; нулевой 256-битный ключ
+		{"serpent", "80000000000000000000000000000000", "00000000000000000000000000000000", "264e5481eff42a4606abda06c0bfda3d"},
+		{"serpent", "0000000000000000000000000000000000000000000000000000000000000000", "00000000000000000000000000000000", "49672ba898d98df95019180445491089"},
+		// Twofish, ecb_tbl.txt: I=1 для каждой длины ключа
+		{"twofish", "00000000000000000000000000000000", "00000000000000000000000000000000", "9f589f5cf6122c32b6bfec2f2ae8c35a"},
+		{"twofish", "000000000000000000000000000000000000000000000000", "00000000000000000000000000000000", "efa71f788965bd4453f860178fc19101"},
+		{"twofish", "0000000000000000000000000000000000000000000000000000000000000000", "00000000000000000000000000000000", "57ff739d4dc92c1bd7fc01700cc8216f"},
+	}
+
+	for _, v := range vectors {
+		key, _ := hex.DecodeString(v.key)
+		pt, _ := hex.DecodeString(v.plaintext)
+		block, err := New(v.name, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := make([]byte, 16)
+		block.Encrypt(out, pt)
+		if got := hex.EncodeToString(out); got != v.ciphertext {
+			t.Fatalf("%s/%d: encrypt got %s, want %s", v.name, len(key)*8, got, v.ciphertext)
+		}
+		block.Decrypt(out, out)
+		if !bytes.Equal(out, pt) {
+			t.Fatalf("%s/%d: decrypt mismatch", v.name, len(key)*8)
+		}
+	}
+}
+
+func TestCiphers_KeySizes(t *testing.T) {
+	for _, name := range []string{"camellia", "aria", "serpent", "twofish", "sm4"} {
+		spec, ok := Lookup(name)
+		if !ok {
+			t.Fatalf("%s: not registered", name)
+		}
+		for _, size := range spec.KeySizes {
+			if _, err := New(name, make([]byte, size)); err != nil {
+				t.Fatalf("%s/%d: %v", name, size*8, err)
+			}
+		}
+		if _, err := New(name, make([]byte, 20)); err == nil {
+			t.Fatalf("%s: expected error for 20-byte key", name)
+		}
+	}
+}
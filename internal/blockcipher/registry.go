@@ -0,0 +1,111 @@
+// Package blockcipher — реестр 128-битных блочных шифров: AES (в том числе собственные
+// реализации), Camellia, ARIA, Serpent, Twofish и SM4.
+package blockcipher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"sort"
+)
+
+// Factory создаёт шифр по ключу; длину ключа проверяет сама реализация.
+type Factory func(key []byte) (cipher.Block, error)
+
+// Spec: запись реестра — размер блока, допустимые длины ключа (байты) и конструктор.
+type Spec struct {
+	BlockSize int
+	KeySizes  []int
+	New       Factory
+}
+
+var registry = map[string]Spec{}
+
+// Register добавляет шифр в реестр; вызывается из init, повторное имя — ошибка программы.
+func Register(name string, spec Spec) {
+	if _, dup := registry[name]; dup {
+		panic("blockcipher: duplicate registration of " + name)
+	}
+	registry[name] = spec
+}
+
+// Lookup возвращает запись реестра.
+func Lookup(name string) (Spec, bool) {
+	spec, ok := registry[name]
+	return spec, ok
+}
+
+// New создаёт шифр name из реестра.
+func New(name string, key []byte) (cipher.Block, error) {
+	spec, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown block cipher %q", name)
+	}
+	return spec.New(key)
+}
+
+func keySizeError(name string, size int) error {
+	return fmt.Errorf("%s: invalid key size %d", name, size)
+}
+
+// Names возвращает зарегистрированные имена по алфавиту.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Реализации AES: все дают одинаковый шифртекст и различаются только устройством.
+const (
+	AESStd          = "aes"    // crypto/aes (AES-NI, где есть)
+	AESGo           = "aes-go" // собственная табличная реализация
+	AESConstantTime = "aes-ct" // собственная, без таблиц и ветвлений по данным
+)
+
+var aesImplementations = []string{AESStd, AESGo, AESConstantTime}
+
+// aesImpl: реализация, которую NewAES отдаёт всем режимам. Выбирается один раз при
+// запуске (--aes-impl), до начала шифрования.
+var aesImpl = AESStd
+
+func init() {
+	aesKeySizes := []int{16, 24, 32}
+	Register(AESStd, Spec{BlockSize: blockSize, KeySizes: aesKeySizes, New: aes.NewCipher})
+	Register(AESGo, Spec{BlockSize: blockSize, KeySizes: aesKeySizes, New: newAESGo})
+	Register(AESConstantTime, Spec{BlockSize: blockSize, KeySizes: aesKeySizes, New: newAESConstantTime})
+
+	Register("camellia", Spec{BlockSize: blockSize, KeySizes: aesKeySizes, New: newCamellia})
+	Register("aria", Spec{BlockSize: blockSize, KeySizes: aesKeySizes, New: newARIA})
+	Register("serpent", Spec{BlockSize: blockSize, KeySizes: aesKeySizes, New: newSerpent})
+	Register("twofish", Spec{BlockSize: blockSize, KeySizes: aesKeySizes, New: newTwofish})
+	Register("sm4", Spec{BlockSize: blockSize, KeySizes: []int{16}, New: newSM4})
+}
+
+// AESImplementations возвращает допустимые значения для SetAESImplementation.
+func AESImplementations() []string {
+	return append([]string(nil), aesImplementations...)
+}
+
+// SetAESImplementation выбирает реализацию AES для NewAES.
+func SetAESImplementation(name string) error {
+	for _, impl := range aesImplementations {
+		if impl == name {
+			aesImpl = name
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown AES implementation %q (aes, aes-go, aes-ct)", name)
+}
+
+// AESImplementation возвращает текущую реализацию AES.
+func AESImplementation() string {
+	return aesImpl
+}
+
+// NewAES: AES-128/192/256 (по длине ключа) в выбранной реализации.
+func NewAES(key []byte) (cipher.Block, error) {
+	return New(aesImpl, key)
+}
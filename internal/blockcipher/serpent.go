@@ -0,0 +1,156 @@
+package blockcipher
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math/bits"
+)
+
+// Serpent: 32 раунда SPN над четырьмя 32-битными словами в «битовом срезе» — i-й бит
+// слов X0..X3 образует i-й 4-битный вход S-блока (X0 — младший бит). Порядок байтов
+// как у NESSIE и libgcrypt: слова little-endian, короткий ключ дополняется 0x01 и нулями.
+
+var serpentSbox = [8][16]byte{
+	{3, 8, 15, 1, 10, 6, 5, 11, 14, 13, 4, 2, 7, 0, 9, 12},
+	{15, 12, 2, 7, 9, 0, 5, 10, 1, 11, 14, 8, 6, 13, 3, 4},
+	{8, 6, 7, 9, 3, 12, 10, 15, 13, 1, 14, 4, 0, 11, 5, 2},
+	{0, 15, 11, 8, 12, 9, 6, 3, 13, 1, 2, 4, 10, 7, 5, 14},
+	{1, 15, 8, 3, 12, 0, 11, 6, 2, 5, 4, 10, 9, 14, 7, 13},
+	{15, 5, 2, 11, 4, 10, 9, 12, 0, 3, 14, 8, 13, 6, 7, 1},
+	{7, 2, 12, 5, 8, 4, 6, 11, 14, 9, 1, 15, 13, 3, 10, 0},
+	{1, 13, 15, 0, 14, 8, 2, 11, 7, 4, 12, 10, 9, 3, 5, 6},
+}
+
+var serpentInvSbox [8][16]byte
+
+func init() {
+	for s := range serpentSbox {
+		for i, v := range serpentSbox[s] {
+			serpentInvSbox[s][v] = byte(i)
+		}
+	}
+}
+
+const serpentPhi = 0x9e3779b9
+
+type serpentCipher struct {
+	k [33][4]uint32
+}
+
+func newSerpent(key []byte) (cipher.Block, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, keySizeError("serpent", len(key))
+	}
+	var full [32]byte
+	copy(full[:], key)
+	if len(key) < 32 {
+		full[len(key)] = 1
+	}
+	var w [8 + 132]uint32
+	for i := 0; i < 8; i++ {
+		w[i] = binary.LittleEndian.Uint32(full[4*i:])
+	}
+	for i := 8; i < len(w); i++ {
+		w[i] = bits.RotateLeft32(w[i-8]^w[i-5]^w[i-3]^w[i-1]^serpentPhi^uint32(i-8), 11)
+	}
+	c := &serpentCipher{}
+	for i := range c.k {
+		k := [4]uint32{w[8+4*i], w[8+4*i+1], w[8+4*i+2], w[8+4*i+3]}
+		serpentSub(&k, &serpentSbox[(35-i)%8])
+		c.k[i] = k
+	}
+	return c, nil
+}
+
+// serpentSub применяет 4-битный S-блок ко всем 32 срезам.
+func serpentSub(x *[4]uint32, s *[16]byte) {
+	var y [4]uint32
+	for i := 0; i < 32; i++ {
+		in := x[0]>>i&1 | (x[1]>>i&1)<<1 | (x[2]>>i&1)<<2 | (x[3]>>i&1)<<3
+		out := uint32(s[in])
+		y[0] |= (out & 1) << i
+		y[1] |= (out >> 1 & 1) << i
+		y[2] |= (out >> 2 & 1) << i
+		y[3] |= (out >> 3 & 1) << i
+	}
+	*x = y
+}
+
+func serpentLT(x *[4]uint32) {
+	x[0] = bits.RotateLeft32(x[0], 13)
+	x[2] = bits.RotateLeft32(x[2], 3)
+	x[1] ^= x[0] ^ x[2]
+	x[3] ^= x[2] ^ x[0]<<3
+	x[1] = bits.RotateLeft32(x[1], 1)
+	x[3] = bits.RotateLeft32(x[3], 7)
+	x[0] ^= x[1] ^ x[3]
+	x[2] ^= x[3] ^ x[1]<<7
+	x[0] = bits.RotateLeft32(x[0], 5)
+	x[2] = bits.RotateLeft32(x[2], 22)
+}
+
+func serpentInvLT(x *[4]uint32) {
+	x[2] = bits.RotateLeft32(x[2], -22)
+	x[0] = bits.RotateLeft32(x[0], -5)
+	x[2] ^= x[3] ^ x[1]<<7
+	x[0] ^= x[1] ^ x[3]
+	x[3] = bits.RotateLeft32(x[3], -7)
+	x[1] = bits.RotateLeft32(x[1], -1)
+	x[3] ^= x[2] ^ x[0]<<3
+	x[1] ^= x[0] ^ x[2]
+	x[2] = bits.RotateLeft32(x[2], -3)
+	x[0] = bits.RotateLeft32(x[0], -13)
+}
+
+func serpentXor(x, k *[4]uint32) {
+	for i := range x {
+		x[i] ^= k[i]
+	}
+}
+
+func (c *serpentCipher) BlockSize() int { return blockSize }
+
+func (c *serpentCipher) Encrypt(dst, src []byte) {
+	x := serpentLoad(src, dst)
+	for r := 0; r < 32; r++ {
+		serpentXor(&x, &c.k[r])
+		serpentSub(&x, &serpentSbox[r%8])
+		if r < 31 {
+			serpentLT(&x)
+		}
+	}
+	serpentXor(&x, &c.k[32])
+	serpentStore(dst, &x)
+}
+
+func (c *serpentCipher) Decrypt(dst, src []byte) {
+	x := serpentLoad(src, dst)
+	serpentXor(&x, &c.k[32])
+	for r := 31; r >= 0; r-- {
+		if r < 31 {
+			serpentInvLT(&x)
+		}
+		serpentSub(&x, &serpentInvSbox[r%8])
+		serpentXor(&x, &c.k[r])
+	}
+	serpentStore(dst, &x)
+}
+
+func serpentLoad(src, dst []byte) [4]uint32 {
+	if len(src) < blockSize || len(dst) < blockSize {
+		panic("blockcipher: input not full block")
+	}
+	var x [4]uint32
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(src[4*i:])
+	}
+	return x
+}
+
+func serpentStore(dst []byte, x *[4]uint32) {
+	for i := range x {
+		binary.LittleEndian.PutUint32(dst[4*i:], x[i])
+	}
+}
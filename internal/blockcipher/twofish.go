@@ -0,0 +1,215 @@
+package blockcipher
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math/bits"
+)
+
+// Twofish (Schneier и др., 1998): 16 раундов сети Фейстеля с ключезависимыми S-блоками.
+// Перестановки q0/q1 строятся из 4-битных таблиц (раздел 4.3.5 описания), ключезависимые
+// S-блоки вместе со столбцами MDS вычисляются один раз при создании шифра.
+
+const (
+	twofishMDSPoly = 0x169 // x^8 + x^6 + x^5 + x^3 + 1
+	twofishRSPoly  = 0x14d // x^8 + x^6 + x^3 + x^2 + 1
+)
+
+// twofishT: 4-битные таблицы t0..t3 для q0 и q1.
+var twofishT = [2][4][16]byte{
+	{
+		{0x8, 0x1, 0x7, 0xd, 0x6, 0xf, 0x3, 0x2, 0x0, 0xb, 0x5, 0x9, 0xe, 0xc, 0xa, 0x4},
+		{0xe, 0xc, 0xb, 0x8, 0x1, 0x2, 0x3, 0x5, 0xf, 0x4, 0xa, 0x6, 0x7, 0x0, 0x9, 0xd},
+		{0xb, 0xa, 0x5, 0xe, 0x6, 0xd, 0x9, 0x0, 0xc, 0x8, 0xf, 0x3, 0x2, 0x4, 0x7, 0x1},
+		{0xd, 0x7, 0xf, 0x4, 0x1, 0x2, 0x6, 0xe, 0x9, 0xb, 0x3, 0x0, 0x8, 0x5, 0xc, 0xa},
+	},
+	{
+		{0x2, 0x8, 0xb, 0xd, 0xf, 0x7, 0x6, 0xe, 0x3, 0x1, 0x9, 0x4, 0x0, 0xa, 0xc, 0x5},
+		{0x1, 0xe, 0x2, 0xb, 0x4, 0xc, 0x3, 0x7, 0x6, 0xd, 0xa, 0x5, 0xf, 0x9, 0x0, 0x8},
+		{0x4, 0xc, 0x7, 0x5, 0x1, 0x6, 0x9, 0xa, 0x0, 0xe, 0xd, 0x8, 0x2, 0xb, 0x3, 0xf},
+		{0xb, 0x9, 0x5, 0x1, 0xc, 0x3, 0xd, 0xe, 0x6, 0x4, 0x7, 0xf, 0x2, 0x0, 0x8, 0xa},
+	},
+}
+
+var twofishQ [2][256]byte
+
+func init() {
+	for q := range twofishQ {
+		t := &twofishT[q]
+		for x := 0; x < 256; x++ {
+			a, b := byte(x>>4), byte(x&15)
+			a, b = a^b, a^ror4(b)^(a<<3&15)
+			a, b = t[0][a], t[1][b]
+			a, b = a^b, a^ror4(b)^(a<<3&15)
+			a, b = t[2][a], t[3][b]
+			twofishQ[q][x] = b<<4 | a
+		}
+	}
+}
+
+func ror4(x byte) byte {
+	return (x>>1 | x<<3) & 15
+}
+
+// twofishQOrder: какая из q0/q1 применяется к байту j на каждом шаге h, начиная
+// с самого внутреннего (для 256-битного ключа — L3); последняя строка — внешний шаг.
+var twofishQOrder = [5][4]int{
+	{0, 0, 1, 1}, // L0
+	{0, 1, 0, 1}, // L1
+	{1, 1, 0, 0}, // L2
+	{1, 0, 0, 1}, // L3
+	{1, 0, 1, 0}, // выход
+}
+
+var twofishMDS = [4][4]byte{
+	{0x01, 0xef, 0x5b, 0x5b},
+	{0x5b, 0xef, 0xef, 0x01},
+	{0xef, 0x5b, 0x01, 0xef},
+	{0xef, 0x01, 0xef, 0x5b},
+}
+
+var twofishRS = [4][8]byte{
+	{0x01, 0xa4, 0x55, 0x87, 0x5a, 0x58, 0xdb, 0x9e},
+	{0xa4, 0x56, 0x82, 0xf3, 0x1e, 0xc6, 0x68, 0xe5},
+	{0x02, 0xa1, 0xfc, 0xc1, 0x47, 0xae, 0x3d, 0x19},
+	{0xa4, 0x55, 0x87, 0x5a, 0x58, 0xdb, 0x9e, 0x03},
+}
+
+// gfMulPoly: произведение в GF(2^8) по модулю poly.
+func gfMulPoly(a, b byte, poly uint16) byte {
+	var p uint16
+	x := uint16(a)
+	for ; b != 0; b >>= 1 {
+		if b&1 != 0 {
+			p ^= x
+		}
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= poly
+		}
+	}
+	return byte(p)
+}
+
+// twofishMDSColumn: вклад байта z в позицию j в произведение MDS · z.
+func twofishMDSColumn(z byte, j int) uint32 {
+	var y uint32
+	for i := 0; i < 4; i++ {
+		y |= uint32(gfMulPoly(z, twofishMDS[i][j], twofishMDSPoly)) << (8 * i)
+	}
+	return y
+}
+
+// twofishChain: байтовая часть функции h для столбца j со словами L (L[0] — внешнее).
+func twofishChain(x byte, j int, l [][4]byte) byte {
+	for i := len(l) - 1; i >= 0; i-- {
+		x = twofishQ[twofishQOrder[i][j]][x] ^ l[i][j]
+	}
+	return twofishQ[twofishQOrder[4][j]][x]
+}
+
+func twofishH(x byte, l [][4]byte) uint32 {
+	var y uint32
+	for j := 0; j < 4; j++ {
+		y ^= twofishMDSColumn(twofishChain(x, j, l), j)
+	}
+	return y
+}
+
+type twofishCipher struct {
+	s [4][256]uint32 // S-блоки g, уже умноженные на столбцы MDS
+	k [40]uint32
+}
+
+func newTwofish(key []byte) (cipher.Block, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, keySizeError("twofish", len(key))
+	}
+	n := len(key) / 8
+	me := make([][4]byte, n)
+	mo := make([][4]byte, n)
+	s := make([][4]byte, n)
+	for i := 0; i < n; i++ {
+		copy(me[i][:], key[8*i:])
+		copy(mo[i][:], key[8*i+4:])
+		// S_i = RS · m[8i..8i+7]; в h идут в обратном порядке.
+		for r := 0; r < 4; r++ {
+			for c := 0; c < 8; c++ {
+				s[n-1-i][r] ^= gfMulPoly(key[8*i+c], twofishRS[r][c], twofishRSPoly)
+			}
+		}
+	}
+
+	c := &twofishCipher{}
+	for i := 0; i < 20; i++ {
+		a := twofishH(byte(2*i), me)
+		b := bits.RotateLeft32(twofishH(byte(2*i+1), mo), 8)
+		c.k[2*i] = a + b
+		c.k[2*i+1] = bits.RotateLeft32(a+2*b, 9)
+	}
+	for j := 0; j < 4; j++ {
+		for x := 0; x < 256; x++ {
+			c.s[j][x] = twofishMDSColumn(twofishChain(byte(x), j, s), j)
+		}
+	}
+	return c, nil
+}
+
+func (c *twofishCipher) g(x uint32) uint32 {
+	return c.s[0][byte(x)] ^ c.s[1][byte(x>>8)] ^ c.s[2][byte(x>>16)] ^ c.s[3][byte(x>>24)]
+}
+
+func (c *twofishCipher) BlockSize() int { return blockSize }
+
+func (c *twofishCipher) Encrypt(dst, src []byte) {
+	if len(src) < blockSize || len(dst) < blockSize {
+		panic("blockcipher: input not full block")
+	}
+	var r [4]uint32
+	for i := range r {
+		r[i] = binary.LittleEndian.Uint32(src[4*i:]) ^ c.k[i]
+	}
+	for round := 0; round < 16; round++ {
+		t0 := c.g(r[0])
+		t1 := c.g(bits.RotateLeft32(r[1], 8))
+		f0 := t0 + t1 + c.k[2*round+8]
+		f1 := t0 + 2*t1 + c.k[2*round+9]
+		r = [4]uint32{
+			bits.RotateLeft32(r[2]^f0, -1),
+			bits.RotateLeft32(r[3], 1) ^ f1,
+			r[0],
+			r[1],
+		}
+	}
+	// Отмена последней перестановки половин и выходное отбеливание.
+	for i := range r {
+		binary.LittleEndian.PutUint32(dst[4*i:], r[(i+2)%4]^c.k[i+4])
+	}
+}
+
+func (c *twofishCipher) Decrypt(dst, src []byte) {
+	if len(src) < blockSize || len(dst) < blockSize {
+		panic("blockcipher: input not full block")
+	}
+	var r [4]uint32
+	for i := range r {
+		r[(i+2)%4] = binary.LittleEndian.Uint32(src[4*i:]) ^ c.k[i+4]
+	}
+	for round := 15; round >= 0; round-- {
+		t0 := c.g(r[2])
+		t1 := c.g(bits.RotateLeft32(r[3], 8))
+		f0 := t0 + t1 + c.k[2*round+8]
+		f1 := t0 + 2*t1 + c.k[2*round+9]
+		r = [4]uint32{
+			r[2],
+			r[3],
+			bits.RotateLeft32(r[0], 1) ^ f0,
+			bits.RotateLeft32(r[1]^f1, -1),
+		}
+	}
+	for i := range r {
+		binary.LittleEndian.PutUint32(dst[4*i:], r[i]^c.k[i])
+	}
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	myhash "cryptcore/internal/hash"
+)
+
+// ChecksumLine: строка списка контрольных сумм для dgst --check.
+type ChecksumLine struct {
+	Algorithm string // из тега BSD ("SHA256 (file) = ..."); пусто для формата GNU
+	Digest    string // hex в нижнем регистре или "sha256-tree-1M:<chunks>:<hex>"
+	Path      string
+}
+
+var errChecksumFormat = errors.New("improperly formatted checksum line")
+
+// ParseChecksumLine разбирает строку в формате GNU coreutils ("<hex>  <путь>", "<hex> *<путь>")
+// или BSD-тегов ("SHA256 (<путь>) = <hex>"). Строка, начинающаяся с '\', содержит путь
+// с экранированием \\, \n и \r, как у sha256sum.
+func ParseChecksumLine(line string) (ChecksumLine, error) {
+	escaped := strings.HasPrefix(line, "\\")
+	if escaped {
+		line = line[1:]
+	}
+
+	var c ChecksumLine
+	if digest, path, ok := cutGNUChecksum(line); ok {
+		c = ChecksumLine{Digest: digest, Path: path}
+	} else if tag, rest, ok := strings.Cut(line, " ("); ok {
+		i := strings.LastIndex(rest, ") = ")
+		if i < 0 || tag == "" {
+			return ChecksumLine{}, errChecksumFormat
+		}
+		algorithm, known := checksumTagAlgorithm(tag)
+		if !known {
+			return ChecksumLine{}, errChecksumFormat
+		}
+		c = ChecksumLine{Algorithm: algorithm, Digest: rest[i+4:], Path: rest[:i]}
+	} else {
+		return ChecksumLine{}, errChecksumFormat
+	}
+
+	digest, ok := normalizeChecksumDigest(c.Digest)
+	if !ok || c.Path == "" {
+		return ChecksumLine{}, errChecksumFormat
+	}
+	c.Digest = digest
+	if escaped {
+		path, ok := unescapeChecksumPath(c.Path)
+		if !ok {
+			return ChecksumLine{}, errChecksumFormat
+		}
+		c.Path = path
+	}
+	return c, nil
+}
+
+// cutGNUChecksum: дайджест и путь разделены двумя символами — пробелом и пробелом
+// (текстовый режим) или звёздочкой (двоичный); у нас режимы не различаются.
+func cutGNUChecksum(line string) (digest, path string, ok bool) {
+	i := strings.IndexByte(line, ' ')
+	if i <= 0 || i+2 > len(line) || (line[i+1] != ' ' && line[i+1] != '*') {
+		return "", "", false
+	}
+	return line[:i], line[i+2:], true
+}
+
+// normalizeChecksumDigest приводит hex к нижнему регистру; у sha256-tree метка размера
+// чанка ("1M") остаётся как есть.
+func normalizeChecksumDigest(digest string) (string, bool) {
+	if strings.HasPrefix(digest, myhash.TreeAlgorithm+"-") {
+		label, rootHex, _ := strings.Cut(digest, ":")
+		digest = label + ":" + strings.ToLower(rootHex)
+		_, _, _, err := myhash.ParseTreeDigest(digest)
+		return digest, err == nil
+	}
+	digest = strings.ToLower(digest)
+	b, err := hex.DecodeString(digest)
+	return digest, err == nil && len(b) > 0
+}
+
+// checksumTagAlgorithm переводит тег BSD (coreutils --tag, openssl) в имя --algorithm.
+func checksumTagAlgorithm(tag string) (string, bool) {
+	name := strings.ToLower(tag)
+	aliases := map[string]string{
+		"blake2b": "blake2b-512", "blake2b512": "blake2b-512",
+		"blake2s": "blake2s-256", "blake2s256": "blake2s-256",
+		"sha512/224": "sha512-224", "sha512/256": "sha512-256",
+		"sha2-512/224": "sha512-224", "sha2-512/256": "sha512-256",
+	}
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	} else if rest, ok := strings.CutPrefix(name, "sha2-"); ok {
+		name = "sha" + rest
+	}
+	if myhash.IsXOF(name) {
+		return name, true
+	}
+	if _, err := myhash.Digest(name); err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// FormatChecksumLine — обратное к ParseChecksumLine для формата GNU: путь с '\', переводом
+// строки или возвратом каретки экранируется, а строка начинается с '\', как у sha256sum.
+func FormatChecksumLine(digest, path string) string {
+	if !strings.ContainsAny(path, "\\\n\r") {
+		return digest + "  " + path + "\n"
+	}
+	path = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r").Replace(path)
+	return "\\" + digest + "  " + path + "\n"
+}
+
+func unescapeChecksumPath(s string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 == len(s) {
+			return "", false
+		}
+		i++
+		switch s[i] {
+		case '\\':
+			b.WriteByte('\\')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
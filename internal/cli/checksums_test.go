@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseChecksumLine(t *testing.T) {
+	const sum = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	cases := []struct {
+		line string
+		want ChecksumLine
+	}{
+		{sum + "  a.txt", ChecksumLine{Digest: sum, Path: "a.txt"}},
+		{sum + " *dir/b c.bin", ChecksumLine{Digest: sum, Path: "dir/b c.bin"}},
+		{"5891B5B522D5DF086D0FF0B110FBD9D21BB4FC7163AF34D08286A2E846F6BE03  a.txt", ChecksumLine{Digest: sum, Path: "a.txt"}},
+		{`\` + sum + `  back\\slash\nline`, ChecksumLine{Digest: sum, Path: "back\\slash\nline"}},
+		{`\` + sum + `  cr\rlf`, ChecksumLine{Digest: sum, Path: "cr\rlf"}},
+		{"SHA256 (a (1).txt) = " + sum, ChecksumLine{Algorithm: "sha256", Digest: sum, Path: "a (1).txt"}},
+		{"SHA2-256 (a.txt) = " + sum, ChecksumLine{Algorithm: "sha256", Digest: sum, Path: "a.txt"}},
+		{"BLAKE2b-256 (a.txt) = " + sum, ChecksumLine{Algorithm: "blake2b-256", Digest: sum, Path: "a.txt"}},
+		{"sha256-tree-1M:58:" + sum + "  big.iso", ChecksumLine{Digest: "sha256-tree-1M:58:" + sum, Path: "big.iso"}},
+	}
+	for _, c := range cases {
+		got, err := ParseChecksumLine(c.line)
+		if err != nil || got != c.want {
+			t.Errorf("%q: got %+v, %v; want %+v", c.line, got, err, c.want)
+		}
+	}
+
+	for _, line := range []string{
+		"garbage",
+		sum + " a.txt", // один пробел
+		sum + "  ",     // пустой путь
+		"xyz  a.txt",   // не hex
+		"MD4 (a.txt) = " + sum,
+		`\` + sum + `  bad\escape`,
+		"sha256-tree-3M:58:" + sum + "  big.iso",
+		"sha256-tree-1M:" + sum + "  big.iso",
+	} {
+		if _, err := ParseChecksumLine(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+}
+
+func TestFormatChecksumLine(t *testing.T) {
+	const sum = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	for _, path := range []string{"a.txt", "dir/b c.bin", "x\ny", "back\\slash", "cr\rlf", "\\\n"} {
+		line := FormatChecksumLine(sum, path)
+		if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+			t.Errorf("%q: not a single line: %q", path, line)
+		}
+		got, err := ParseChecksumLine(strings.TrimSuffix(line, "\n"))
+		if err != nil || got != (ChecksumLine{Digest: sum, Path: path}) {
+			t.Errorf("%q: round trip got %+v, %v", path, got, err)
+		}
+	}
+	if got := FormatChecksumLine(sum, "x\ny"); got != `\`+sum+`  x\ny`+"\n" {
+		t.Errorf("escaped line: %q", got)
+	}
+}
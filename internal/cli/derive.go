@@ -4,6 +4,8 @@ import (
 	"encoding/hex"
 	"flag"
 	"fmt"
+
+	myhash "cryptcore/internal/hash"
 )
 
 type DeriveOptions struct {
@@ -12,6 +14,7 @@ type DeriveOptions struct {
 	Iterations int
 	Length     int
 	Algorithm  string
+	Hash       string // хеш-функция HMAC в PBKDF2
 	OutputPath string
 }
 
@@ -23,6 +26,7 @@ func ParseDeriveArgs(args []string) (*DeriveOptions, error) {
 	iterations := fs.Int("iterations", 100000, "Iteration count")
 	length := fs.Int("length", 32, "Derived key length in bytes")
 	algorithm := fs.String("algorithm", "pbkdf2", "KDF algorithm (pbkdf2)")
+	hashName := fs.String("hash", "sha256", "PRF hash for PBKDF2-HMAC (sha224, sha256, sha384, sha512, sha512-224, sha512-256, sha3-224, sha3-256, sha3-384, sha3-512, blake2b-*, blake2s-*)")
 	output := fs.String("output", "", "Write derived key to file as raw bytes (optional)")
 
 	if err := fs.Parse(args); err != nil {
@@ -41,6 +45,9 @@ func ParseDeriveArgs(args []string) (*DeriveOptions, error) {
 	if *algorithm != "pbkdf2" {
 		return nil, fmt.Errorf("unsupported algorithm: must be pbkdf2")
 	}
+	if _, err := myhash.Digest(*hashName); err != nil {
+		return nil, err
+	}
 
 	// Если salt задан — проверим, что это hex
 	if *salt != "" {
@@ -55,6 +62,7 @@ func ParseDeriveArgs(args []string) (*DeriveOptions, error) {
 		Iterations: *iterations,
 		Length:     *length,
 		Algorithm:  *algorithm,
+		Hash:       *hashName,
 		OutputPath: *output,
 	}, nil
 }
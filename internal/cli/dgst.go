@@ -1,48 +1,235 @@
 package cli
 
 import (
+	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	myfs "cryptcore/internal/fs"
+	myhash "cryptcore/internal/hash"
 )
 
 type DgstOptions struct {
-	Algorithm  string
-	InputPath  string
-	OutputPath string
+	Algorithm     string
+	InputPath     string   // единственный вход (--proof, --verify)
+	InputPaths    []string // --input и позиционные аргументы: файлы, каталоги, шаблоны
+	OutputPath    string
+	Length        int    // длина выхода XOF в байтах (0 — по умолчанию)
+	Customization string // строка S для cSHAKE
+	Key           []byte // ключ BLAKE3 (keyed hash)
+	Context       string // контекст BLAKE3 (derive-key)
+	ChunkSize     int    // размер чанка sha256-tree
+	Verify        string // ожидаемый дайджест "sha256-tree-1M:<chunks>:<hex>" для проверки
+	Proof         bool   // вывести доказательство включения чанка Chunk
+	Chunk         int
+	Check         string // список контрольных сумм для проверки ("-" — stdin)
+	Quiet         bool   // --check: не печатать OK
+	Status        bool   // --check: ничего не печатать, только код возврата
+	Strict        bool   // --check: ошибка при неверно оформленных строках
+	Walk          myfs.WalkOptions
+	Jobs          int // число файлов, хешируемых одновременно
+	Workers       int // горутин на чанки одного файла (0 — по числу CPU); задаёт hashFiles
+}
+
+// maxDigestLength: предел --length; выход XOF выделяется целиком и печатается в hex.
+const maxDigestLength = 64 * 1024
+
+// stringList — повторяемый флаг (--exclude a --exclude b).
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(v string) error { *l = append(*l, v); return nil }
+
+// parseInterspersed разбирает флаги вперемешку с позиционными аргументами
+// (dgst -r dir --algorithm blake3 file); после "--" всё считается путями.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flags.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if len(args) > len(rest) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
 }
 
 func ParseDgstArgs(args []string) (*DgstOptions, error) {
 	fs := flag.NewFlagSet("dgst", flag.ContinueOnError)
-	algorithm := fs.String("algorithm", "sha256", "Hash algorithm (sha256, par-sha256, sha512)")
+	algorithm := fs.String("algorithm", "sha256", "Hash algorithm (sha224, sha256, sha384, sha512, sha512-224, sha512-256, sha3-224, sha3-256, sha3-384, sha3-512, shake128, shake256, cshake128, cshake256, blake2b-160/256/384/512, blake2s-128/160/224/256, blake3, sha256-tree, par-sha256)")
 	input := fs.String("input", "", "Input file path")
 	output := fs.String("output", "", "Output file path (optional)")
+	length := fs.Int("length", 0, "output length in bytes for shake/cshake (default 32 for *128, 64 for *256) and blake3 (default 32)")
+	customization := fs.String("customization", "", "customization string S for cshake128/cshake256")
+	key := fs.String("key", "", "hex 32-byte key for blake3 keyed hashing")
+	context := fs.String("context", "", "context string for blake3 derive-key mode (the input file is the key material)")
+	chunkSize := fs.String("chunk-size", "", "chunk size for sha256-tree: power of two from 1K to 64M, e.g. 64K, 1M (default 1M)")
+	verify := fs.String("verify", "", "check the input against a sha256-tree digest such as sha256-tree-1M:<chunks>:<hex>")
+	proof := fs.Bool("proof", false, "print a sha256-tree inclusion proof for --chunk instead of the digest")
+	chunkIndex := fs.Int("chunk", -1, "zero-based chunk number for --proof")
+	check := fs.String("check", "", "read checksums from the file ('-' for stdin) and check them (GNU or BSD-tag format)")
+	quiet := fs.Bool("quiet", false, "--check: don't print OK for each successfully verified file")
+	status := fs.Bool("status", false, "--check: don't output anything, the exit status shows success")
+	strict := fs.Bool("strict", false, "--check: exit non-zero for improperly formatted checksum lines")
+	var recursive bool
+	fs.BoolVar(&recursive, "r", false, "hash files in directories recursively, in sorted order")
+	fs.BoolVar(&recursive, "recursive", false, "same as -r")
+	symlinks := fs.String("symlinks", "skip", "symbolic links met while walking directories: skip or follow")
+	hidden := fs.Bool("hidden", false, "include names starting with '.' when walking directories and expanding patterns")
+	var exclude stringList
+	fs.Var(&exclude, "exclude", "skip names or relative paths matching the pattern (repeatable)")
+	jobs := fs.Int("jobs", runtime.NumCPU(), "number of files hashed concurrently")
 
-	if err := fs.Parse(args); err != nil {
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
 		return nil, err
 	}
+	var paths []string
+	if *input != "" {
+		paths = append(paths, *input)
+	}
+	paths = append(paths, positional...)
 
-	if *input == "" {
+	if *check != "" {
+		if len(paths) > 0 || *output != "" || *proof || *verify != "" || recursive {
+			return nil, fmt.Errorf("--check takes file names from the checksum list; input paths, --output, -r, --proof and --verify are not used")
+		}
+	} else if *quiet || *status || *strict {
+		return nil, fmt.Errorf("--quiet, --status and --strict are only used with --check")
+	} else if len(paths) == 0 {
 		return nil, fmt.Errorf("input file is required")
 	}
+	if (*proof || *verify != "") && (len(paths) != 1 || recursive) {
+		return nil, fmt.Errorf("--proof and --verify take exactly one input file")
+	}
+	if *symlinks != "skip" && *symlinks != "follow" {
+		return nil, fmt.Errorf("--symlinks must be skip or follow")
+	}
+	if *jobs < 1 {
+		return nil, fmt.Errorf("--jobs must be > 0")
+	}
+	for _, pattern := range exclude {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid --exclude pattern %q: %v", pattern, err)
+		}
+	}
+	var inputPath string
+	if len(paths) == 1 {
+		inputPath = paths[0]
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
 
-	if *algorithm != "sha256" && *algorithm != "sha512" && *algorithm != "par-sha256" {
-		return nil, fmt.Errorf("unsupported algorithm: must be sha256, par-sha256 or sha512")
+	if *proof && *verify != "" {
+		return nil, fmt.Errorf("--proof and --verify are mutually exclusive")
+	}
+	if *proof != (*chunkIndex >= 0) {
+		return nil, fmt.Errorf("--proof requires --chunk N (and --chunk is only used with --proof)")
+	}
+	if (*proof || *verify != "") && set["algorithm"] && *algorithm != myhash.TreeAlgorithm {
+		return nil, fmt.Errorf("--proof and --verify only work with sha256-tree")
+	}
+	if *proof {
+		*algorithm = myhash.TreeAlgorithm
+	}
+
+	chunk := myhash.ChunkSize
+	if *verify != "" {
+		// алгоритм и размер чанка берутся из самой строки дайджеста
+		if *chunkSize != "" {
+			return nil, fmt.Errorf("--chunk-size is taken from the --verify digest")
+		}
+		var err error
+		if chunk, _, _, err = myhash.ParseTreeDigest(*verify); err != nil {
+			return nil, err
+		}
+		*algorithm = myhash.TreeAlgorithm
+	}
+	if *chunkSize != "" {
+		if *algorithm != myhash.TreeAlgorithm {
+			return nil, fmt.Errorf("--chunk-size is only used with sha256-tree")
+		}
+		var err error
+		if chunk, err = myhash.ParseChunkSize(*chunkSize); err != nil {
+			return nil, err
+		}
+	}
+
+	if *algorithm != "par-sha256" && *algorithm != myhash.TreeAlgorithm && !myhash.IsXOF(*algorithm) {
+		if _, err := myhash.Digest(*algorithm); err != nil {
+			return nil, err
+		}
+	}
+	if *length != 0 && !myhash.IsXOF(*algorithm) && *algorithm != "blake3" {
+		return nil, fmt.Errorf("--length is only used with shake128, shake256, cshake128, cshake256 and blake3")
+	}
+	if *length < 0 {
+		return nil, fmt.Errorf("--length must be > 0")
+	}
+	if *length > maxDigestLength {
+		return nil, fmt.Errorf("--length must be at most %d bytes", maxDigestLength)
+	}
+	if *customization != "" && *algorithm != "cshake128" && *algorithm != "cshake256" {
+		return nil, fmt.Errorf("--customization is only used with cshake128 and cshake256")
+	}
+	if (*key != "" || *context != "") && *algorithm != "blake3" {
+		return nil, fmt.Errorf("--key and --context are only used with blake3")
+	}
+	if *key != "" && *context != "" {
+		return nil, fmt.Errorf("--key and --context are mutually exclusive")
+	}
+	var keyBytes []byte
+	if *key != "" {
+		var err error
+		if keyBytes, err = hex.DecodeString(*key); err != nil || len(keyBytes) != 32 {
+			return nil, fmt.Errorf("--key must be 32 bytes in hex")
+		}
 	}
 
 	return &DgstOptions{
-		Algorithm:  *algorithm,
-		InputPath:  *input,
-		OutputPath: *output,
+		Algorithm:     *algorithm,
+		InputPath:     inputPath,
+		InputPaths:    paths,
+		OutputPath:    *output,
+		Length:        *length,
+		Customization: *customization,
+		Key:           keyBytes,
+		Context:       *context,
+		ChunkSize:     chunk,
+		Verify:        *verify,
+		Proof:         *proof,
+		Chunk:         *chunkIndex,
+		Check:         *check,
+		Quiet:         *quiet,
+		Status:        *status,
+		Strict:        *strict,
+		Walk: myfs.WalkOptions{
+			Recursive:      recursive,
+			FollowSymlinks: *symlinks == "follow",
+			Hidden:         *hidden,
+			Exclude:        exclude,
+		},
+		Jobs: *jobs,
 	}, nil
 }
 
 func validateDgstOptions(o *DgstOptions) error {
 	if o.Algorithm == "" {
-		return errors.New("--algorithm is required (sha256, sha512)")
+		return errors.New("--algorithm is required (sha224, sha256, sha384, sha512, sha512-224, sha512-256)")
 	}
-	if o.Algorithm != "sha256" && o.Algorithm != "sha512" {
-		return errors.New("unsupported algorithm: must be sha256 or sha512")
+	if _, err := myhash.Digest(o.Algorithm); err != nil {
+		return err
 	}
 	if o.InputPath == "" {
 		return errors.New("--input is required")
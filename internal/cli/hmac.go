@@ -1,28 +1,37 @@
 package cli
 
 import (
+	"bufio"
+	"cryptcore/internal/crypto"
 	myhash "cryptcore/internal/hash" // Алиас для твоего пакета
 	"cryptcore/internal/mac"
-	"crypto/sha512"
 	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"hash" // Стандартный интерфейс
 	"io"
 	"os"
+	"strings"
 )
 
 // HMACCmd реализует подкоманду hmac
 func HMACCmd(args []string) {
 	fs := flag.NewFlagSet("hmac", flag.ExitOnError)
-	algorithm := fs.String("algorithm", "sha256", "Hash algorithm: sha256 or sha512")
+	algorithm := fs.String("algorithm", "sha256", "MAC algorithm: sha224, sha256, sha384, sha512, sha512-224, sha512-256, sha3-224, sha3-256, sha3-384, sha3-512 (HMAC), blake2b-*/blake2s-* (keyed BLAKE2), blake3 (keyed BLAKE3, 32-byte key), cmac-aes, poly1305 or gmac-aes")
 	input := fs.String("input", "", "Input file")
 	key := fs.String("key", "", "Secret key (hex encoded or plain string)")
+	nonce := fs.String("nonce", "", "hex nonce for poly1305 (12 bytes) and gmac-aes (12 bytes recommended)")
+	manifest := fs.String("manifest", "", "file with '<nonce-hex> <path>' lines (poly1305, gmac-aes); nonces must be unique")
 
 	fs.Parse(args)
 
-	if *input == "" {
-		fmt.Println("Error: --input is required")
+	if *input == "" && *manifest == "" {
+		fmt.Println("Error: --input or --manifest is required")
+		os.Exit(1)
+	}
+	if *input != "" && *manifest != "" {
+		fmt.Println("Error: --input and --manifest are mutually exclusive")
 		os.Exit(1)
 	}
 	if *key == "" {
@@ -36,37 +45,159 @@ func HMACCmd(args []string) {
 		keyBytes = []byte(*key)
 	}
 
-	// Функция-конструктор, возвращающая hash.Hash
-	var h func() hash.Hash
+	nonceBased := *algorithm == "poly1305" || *algorithm == "gmac-aes"
+	switch {
+	case !nonceBased && (*nonce != "" || *manifest != ""):
+		fmt.Println("Error: --nonce and --manifest are only used with poly1305 and gmac-aes")
+		os.Exit(1)
+	case nonceBased && *manifest == "" && *nonce == "":
+		fmt.Printf("Error: %s requires --nonce; a nonce must never be reused with the same key\n", *algorithm)
+		os.Exit(1)
+	case *manifest != "" && *nonce != "":
+		fmt.Println("Error: with --manifest nonces are read from the manifest")
+		os.Exit(1)
+	}
 
-	switch *algorithm {
-	case "sha256":
-		// Используем адаптер, чтобы превратить твой *DigestSHA256 в hash.Hash
-		h = func() hash.Hash { return myhash.NewSHA256() }
-	case "sha512":
-		h = sha512.New
-	default:
-		fmt.Printf("Error: unknown algorithm %s\n", *algorithm)
+	if *manifest != "" {
+		entries, err := readMACManifest(*manifest)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, e := range entries {
+			tag, err := macFile(*algorithm, keyBytes, e.nonce, e.path)
+			if err != nil {
+				fmt.Printf("Error: %s: %v\n", e.path, err)
+				os.Exit(1)
+			}
+			fmt.Printf("%x  %s\n", tag, e.path)
+		}
+		return
+	}
+
+	var nonceBytes []byte
+	if *nonce != "" {
+		if nonceBytes, err = hex.DecodeString(*nonce); err != nil {
+			fmt.Printf("Error: invalid --nonce hex: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	tag, err := macFile(*algorithm, keyBytes, nonceBytes, *input)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Создаем HMAC
-	hm := mac.New(h, keyBytes)
+	// Выводим результат
+	fmt.Printf("%x  %s\n", tag, *input)
+}
+
+// newMAC создаёт MAC для --algorithm; nonce используется только poly1305 и gmac-aes.
+func newMAC(algorithm string, key, nonce []byte) (hash.Hash, error) {
+	switch algorithm {
+	case "cmac-aes":
+		// длина ключа выбирает AES-128/192/256
+		if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+			return nil, fmt.Errorf("cmac-aes key must be 16, 24 or 32 bytes, got %d", len(key))
+		}
+		return mac.NewCMAC(key)
+	case "poly1305":
+		// одноразовый ключ выводится из --key и nonce (RFC 8439, 2.6)
+		otk, err := crypto.Poly1305KeyGen(key, nonce)
+		if err != nil {
+			return nil, err
+		}
+		return mac.NewPoly1305(otk)
+	case "gmac-aes":
+		if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+			return nil, fmt.Errorf("gmac-aes key must be 16, 24 or 32 bytes, got %d", len(key))
+		}
+		return mac.NewGMAC(key, nonce)
+	default:
+		// BLAKE2 принимает ключ сам (RFC 7693, 2.9), HMAC поверх него не нужен
+		if myhash.IsBLAKE2(algorithm) {
+			return myhash.NewBLAKE2ByName(algorithm, key)
+		}
+		if algorithm == "blake3" {
+			h, err := myhash.NewBLAKE3Keyed(key)
+			if err != nil {
+				return nil, err
+			}
+			return h, nil
+		}
+		// HMAC над любой хеш-функцией из dgst (SHA-2, SHA-3)
+		newHash, err := myhash.Digest(algorithm)
+		if err != nil {
+			return nil, err
+		}
+		return mac.New(newHash, key), nil
+	}
+}
+
+func macFile(algorithm string, key, nonce []byte, path string) ([]byte, error) {
+	hm, err := newMAC(algorithm, key, nonce)
+	if err != nil {
+		return nil, err
+	}
 
 	// Открываем файл
-	file, err := os.Open(*input)
+	file, err := os.Open(path)
 	if err != nil {
-		fmt.Printf("Error opening file: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("error opening file: %w", err)
 	}
 	defer file.Close()
 
 	// Читаем и хешируем
-	if _, err := io.Copy(hm, file); err != nil {
-		fmt.Printf("Error reading file: %v\n", err)
-		os.Exit(1)
+	if _, err := io.CopyBuffer(hm, file, make([]byte, 64*1024)); err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
 	}
+	return hm.Sum(nil), nil
+}
 
-	// Выводим результат
-	fmt.Printf("%x  %s\n", hm.Sum(nil), *input)
+type macManifestEntry struct {
+	nonce []byte
+	path  string
+}
+
+// readMACManifest читает строки "<nonce-hex> <path>" (пустые строки и # — комментарии).
+// Весь манифест проверяется до вычисления первого тега: повтор nonce под одним ключом
+// ломает и Poly1305, и GMAC, поэтому такой манифест отвергается целиком.
+func readMACManifest(path string) ([]macManifestEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening manifest: %w", err)
+	}
+	defer f.Close()
+
+	var entries []macManifestEntry
+	seen := make(map[string]int)
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		nonceHex, file, ok := strings.Cut(text, " ")
+		file = strings.TrimSpace(file)
+		if !ok || file == "" {
+			return nil, fmt.Errorf("manifest line %d: expected '<nonce-hex> <path>'", line)
+		}
+		nonce, err := hex.DecodeString(nonceHex)
+		if err != nil || len(nonce) == 0 {
+			return nil, fmt.Errorf("manifest line %d: invalid nonce %q", line, nonceHex)
+		}
+		key := string(nonce)
+		if first, dup := seen[key]; dup {
+			return nil, fmt.Errorf("manifest line %d: nonce %x reused (first used on line %d)", line, nonce, first)
+		}
+		seen[key] = line
+		entries = append(entries, macManifestEntry{nonce: nonce, path: file})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("error reading manifest: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("manifest is empty")
+	}
+	return entries, nil
 }
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+)
+
+type KeywrapOptions struct {
+	Wrap       bool
+	KEK        []byte
+	Pad        bool
+	InputPath  string
+	OutputPath string
+}
+
+func ParseKeywrapArgs(args []string) (*KeywrapOptions, error) {
+	fs := flag.NewFlagSet("keywrap", flag.ContinueOnError)
+
+	wrap := fs.Bool("wrap", false, "wrap the key from --input")
+	unwrap := fs.Bool("unwrap", false, "unwrap the key from --input")
+	kek := fs.String("kek", "", "key-encryption key as hex (16/24/32 bytes)")
+	pad := fs.Bool("pad", false, "AES Key Wrap with Padding (RFC 5649) for keys of any length")
+	input := fs.String("input", "", "file with the raw key (--wrap) or the wrapped key (--unwrap)")
+	output := fs.String("output", "", "write the result to file as raw bytes (optional; hex to stdout otherwise)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *wrap == *unwrap {
+		return nil, fmt.Errorf("exactly one of --wrap or --unwrap must be set")
+	}
+	if *input == "" {
+		return nil, fmt.Errorf("input file is required")
+	}
+	kekBytes, err := hex.DecodeString(*kek)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kek hex: %v", err)
+	}
+	if len(kekBytes) != 16 && len(kekBytes) != 24 && len(kekBytes) != 32 {
+		return nil, fmt.Errorf("kek must be 16, 24 or 32 bytes (32, 48 or 64 hex chars)")
+	}
+
+	return &KeywrapOptions{
+		Wrap:       *wrap,
+		KEK:        kekBytes,
+		Pad:        *pad,
+		InputPath:  *input,
+		OutputPath: *output,
+	}, nil
+}
@@ -4,6 +4,12 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cryptcore/internal/blockcipher"
+	"cryptcore/internal/crypto"
 )
 
 type Options struct {
@@ -17,24 +23,71 @@ type Options struct {
 	IVHex      string
 	UseIVFlag  bool
 	Password   string
+	AAD        string
+	UseAADFlag bool // --aad задан, возможно пустым: для siv пустой AD отличается от его отсутствия
+	KeySize    int
+	Legacy     bool
+	Iterations int
+	Stream     bool
+	InMemory   bool // расшифровать AEAD с одним тегом больше предела, прочитав файл целиком
+	ChunkSize  int
+	MAC        string
+	SectorSize int
+	Padding    string
+	AESImpl    string
 }
 
+// Число итераций PBKDF2 по умолчанию: для нового формата оно пишется в заголовок,
+// старый формат без заголовка всегда использовал 4096. MaxIterations ограничивает
+// и флаг, и значение из заголовка: иначе подделанный файл занял бы CPU на часы
+// ещё до проверки ключа.
+const (
+	DefaultIterations       = 100000
+	DefaultLegacyIterations = 4096
+	MaxIterations           = 10 * DefaultIterations
+)
+
 func ParseArgs(args []string) (*Options, error) {
 	fs := flag.NewFlagSet("cryptocore", flag.ContinueOnError)
-	algo := fs.String("algorithm", "", "cipher algorithm (must be aes)")
-	mode := fs.String("mode", "", "mode of operation (ecb, cbc, cfb, ofb, ctr)")
+	algo := fs.String("algorithm", "", "cipher algorithm (aes-128, aes-192, aes-256, camellia-128/192/256, aria-128/192/256, serpent-128/192/256, twofish-128/192/256, sm4, chacha20-poly1305, xchacha20-poly1305; aes = aes-128)")
+	mode := fs.String("mode", "", "mode of operation (ecb, cbc, cfb, cfb8, cfb1, ofb, ctr, gcm, siv, xts); not used with chacha20-poly1305")
 	encrypt := fs.Bool("encrypt", false, "encrypt")
 	decrypt := fs.Bool("decrypt", false, "decrypt")
-	key := fs.String("key", "", "hex-encoded AES-128 key (16 bytes => 32 hex chars)")
+	key := fs.String("key", "", "hex-encoded key (16/24/32 bytes for AES, 32 bytes for ChaCha20)")
 	input := fs.String("input", "", "input file path")
 	output := fs.String("output", "", "output file path")
 	iv := fs.String("iv", "", "hex-encoded 16-byte IV (for decryption in CBC/CFB/OFB/CTR)")
 	password := fs.String("password", "", "Password for key derivation")
+	aad := fs.String("aad", "", "associated data for AEAD ciphers: hex string or file path (optional)")
+	legacy := fs.Bool("legacy", false, "use the old headerless layout [salt][IV][ciphertext]")
+	iterations := fs.Int("iterations", 0, "PBKDF2 iterations for --password (default 100000; 4096 with --legacy)")
+	stream := fs.Bool("stream", false, "chunked AEAD format: each chunk is authenticated separately (gcm, chacha20-poly1305)")
+	inMemory := fs.Bool("in-memory", false, "decrypt a single-tag AEAD file larger than 256 MiB by reading it whole into memory")
+	macAlg := fs.String("mac", "", "encrypt-then-MAC for cbc/cfb/ofb/ctr (hmac-sha256, hmac-sha512)")
+	chunkSize := fs.String("chunk-size", "64K", "chunk size for --stream (bytes, K or M suffix)")
+	sectorSize := fs.String("sector-size", "", "sector size for xts (bytes, K suffix; default 512)")
+	aesImpl := fs.String("aes-impl", blockcipher.AESStd, "AES implementation: aes (crypto/aes), aes-go (in-tree), aes-ct (in-tree, constant-time)")
+	padding := fs.String("padding", "", "last-block handling for ecb/cbc: pkcs7 (default), ansix923, iso7816, iso10126, zero, none, cts-cs1, cts-cs2, cts-cs3")
 
 	if err := fs.Parse(args); err != nil {
 		return nil, err
 	}
 
+	aadSet := false
+	fs.Visit(func(f *flag.Flag) { aadSet = aadSet || f.Name == "aad" })
+
+	chunk, err := parseSize(*chunkSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --chunk-size: %v", err)
+	}
+
+	sector := crypto.DefaultXTSSectorSize
+	if *sectorSize != "" {
+		if sector, err = parseSize(*sectorSize); err != nil {
+			return nil, fmt.Errorf("invalid --sector-size: %v", err)
+		}
+	}
+
 	opts := &Options{
 		Algorithm:  *algo,
 		Mode:       *mode,
@@ -46,6 +99,21 @@ func ParseArgs(args []string) (*Options, error) {
 		IVHex:      *iv,
 		UseIVFlag:  *iv != "",
 		Password:   *password,
+		AAD:        *aad,
+		UseAADFlag: aadSet,
+		Legacy:     *legacy,
+		Iterations: *iterations,
+		Stream:     *stream,
+		InMemory:   *inMemory,
+		ChunkSize:  chunk,
+		MAC:        *macAlg,
+		SectorSize: sector,
+		Padding:    *padding,
+		AESImpl:    *aesImpl,
+	}
+
+	if *sectorSize != "" && opts.Mode != "xts" {
+		return nil, errors.New("--sector-size is only used with --mode xts")
 	}
 
 	// Валидация: Нельзя указывать и --key, и --password одновременно.
@@ -60,12 +128,6 @@ func ParseArgs(args []string) (*Options, error) {
 }
 
 func validateOptions(o *Options) error {
-	if o.Algorithm != "aes" {
-		return errors.New("only --algorithm aes is supported")
-	}
-	if o.Mode == "" {
-		return errors.New("--mode is required (ecb, cbc, cfb, ofb, ctr)")
-	}
 	if o.Encrypt == o.Decrypt {
 		return errors.New("exactly one of --encrypt or --decrypt must be set")
 	}
@@ -73,20 +135,167 @@ func validateOptions(o *Options) error {
 		return errors.New("--input is required")
 	}
 
+	// При расшифровании контейнера алгоритм и режим берутся из заголовка
+	needsParams := o.Encrypt || o.Legacy
+	if o.Algorithm != "" || needsParams {
+		if _, err := crypto.KeySizeForAlgorithm(o.Algorithm); err != nil {
+			return err
+		}
+	}
+	// ChaCha20-Poly1305 сам является AEAD: режим не выбирается
+	if crypto.IsChaChaAlgorithm(o.Algorithm) {
+		if o.Mode != "" && o.Mode != crypto.ModeAEAD {
+			return fmt.Errorf("--mode is not used with %s", o.Algorithm)
+		}
+		o.Mode = crypto.ModeAEAD
+	} else if o.Mode == crypto.ModeAEAD {
+		return errors.New("--mode aead is only valid for chacha20-poly1305 and xchacha20-poly1305")
+	}
+	if o.Mode == "" && needsParams {
+		return errors.New("--mode is required (ecb, cbc, cfb, cfb8, cfb1, ofb, ctr, gcm, siv, xts)")
+	}
+	if o.Algorithm != "" && o.Mode != "" {
+		if err := crypto.CheckAlgorithmMode(o.Algorithm, o.Mode); err != nil {
+			return err
+		}
+	}
+	if o.Algorithm != "" {
+		o.KeySize, _ = crypto.KeySize(o.Algorithm, o.Mode)
+	}
+
 	// Ключ обязателен только если нет пароля и мы расшифровываем (или если шифруем и не хотим генерить)
 	// Для Decrypt нужен либо KeyHex, либо Password
 	if o.Decrypt && o.KeyHex == "" && o.Password == "" {
 		return errors.New("either --key or --password is mandatory for decryption")
 	}
 
+	if o.Iterations < 0 {
+		return errors.New("--iterations must be > 0")
+	}
+	if o.Iterations > MaxIterations {
+		return fmt.Errorf("--iterations must be at most %d", MaxIterations)
+	}
+	if o.Iterations != 0 && o.Password == "" {
+		return errors.New("--iterations requires --password")
+	}
+	if o.Iterations != 0 && o.Decrypt && !o.Legacy {
+		return errors.New("--iterations is read from the file header; it is only needed with --legacy")
+	}
+	if o.Iterations == 0 {
+		o.Iterations = DefaultIterations
+		if o.Legacy {
+			o.Iterations = DefaultLegacyIterations
+		}
+	}
+
 	// IV-логика
 	if o.Mode == "ecb" && o.UseIVFlag {
 		return errors.New("--iv is not allowed in ECB mode")
 	}
-	// При шифровании с паролем IV тоже генерируется, но это handled in main
+	// IV при шифровании всегда генерируется
 	if o.Mode != "ecb" && o.Encrypt && o.UseIVFlag {
 		return errors.New("--iv must not be provided in encryption mode; IV is generated automatically")
 	}
+	if o.UseIVFlag && !o.Legacy {
+		return errors.New("--iv is only used with --legacy; the IV is stored in the file header")
+	}
+	// AEAD: nonce всегда хранится в файле, AAD допустим только для AEAD
+	if crypto.IsAEAD(o.Algorithm, o.Mode) && o.UseIVFlag {
+		return errors.New("--iv is not supported for AEAD ciphers; nonce is read from the file")
+	}
+	if o.Mode != "" && !crypto.IsAEAD(o.Algorithm, o.Mode) && o.UseAADFlag {
+		return errors.New("--aad is only supported for AEAD ciphers (gcm, siv, chacha20-poly1305)")
+	}
+
+	// STREAM: только для AEAD и только в контейнере; при расшифровании формат читается из заголовка
+	if o.Stream {
+		if o.Decrypt {
+			return errors.New("--stream is read from the file header; it is only used for encryption")
+		}
+		if o.Legacy {
+			return errors.New("--stream cannot be used with --legacy")
+		}
+		if !crypto.IsAEAD(o.Algorithm, o.Mode) {
+			return errors.New("--stream requires an AEAD cipher (gcm, chacha20-poly1305)")
+		}
+		if o.Mode == "siv" {
+			return errors.New("--stream is not supported for siv: it is deterministic and has no nonce to number chunks")
+		}
+		if o.ChunkSize < crypto.MinStreamChunkSize || o.ChunkSize > crypto.MaxStreamChunkSize {
+			return fmt.Errorf("--chunk-size must be between %d and %d bytes", crypto.MinStreamChunkSize, crypto.MaxStreamChunkSize)
+		}
+	}
+
+	// --in-memory: только для расшифрования старых файлов без --stream; шифрование больших
+	// файлов требует --stream
+	if o.InMemory && !o.Decrypt {
+		return errors.New("--in-memory is only used with --decrypt; encrypt large files with --stream")
+	}
+
+	// XTS: ключ K1||K2, стандарт (IEEE 1619) определяет только AES-128 и AES-256
+	if o.Mode == "xts" {
+		if o.Algorithm == "aes-192" {
+			return errors.New("xts supports aes-128 and aes-256 only")
+		}
+		if o.SectorSize < crypto.BlockSize || o.SectorSize > crypto.MaxXTSSectorSize || o.SectorSize%crypto.BlockSize != 0 {
+			return fmt.Errorf("--sector-size must be a multiple of 16 between 16 and %d", crypto.MaxXTSSectorSize)
+		}
+	}
+
+	// --aes-impl влияет только на скорость и устойчивость к атакам по времени: шифртекст тот же
+	if !slices.Contains(blockcipher.AESImplementations(), o.AESImpl) {
+		return fmt.Errorf("unknown --aes-impl %q (aes, aes-go, aes-ct)", o.AESImpl)
+	}
+
+	// --padding: пусто — PKCS#7; при расшифровании контейнера берётся из заголовка
+	if o.Padding != "" {
+		if o.Decrypt && !o.Legacy {
+			return errors.New("--padding is read from the file header; it is only needed with --legacy")
+		}
+		if !crypto.IsPadding(o.Padding) {
+			return fmt.Errorf("unsupported --padding %q (pkcs7, ansix923, iso7816, iso10126, zero, none, cts-cs1, cts-cs2, cts-cs3)", o.Padding)
+		}
+		if o.Mode != "ecb" && o.Mode != "cbc" {
+			return errors.New("--padding is only used with ecb and cbc modes")
+		}
+	}
+
+	// Encrypt-then-MAC: только для потоковых режимов без аутентификации
+	if o.MAC != "" {
+		if o.Decrypt {
+			return errors.New("--mac is read from the file header; it is only used for encryption")
+		}
+		if o.Legacy {
+			return errors.New("--mac cannot be used with --legacy")
+		}
+		if !crypto.IsEtMMAC(o.MAC) {
+			return fmt.Errorf("unsupported --mac %q (hmac-sha256, hmac-sha512)", o.MAC)
+		}
+		switch o.Mode {
+		case "cbc", "cfb", "cfb8", "cfb1", "ofb", "ctr":
+		default:
+			return errors.New("--mac is supported for cbc, cfb, cfb8, cfb1, ofb and ctr modes")
+		}
+	}
 
 	return nil
 }
+
+// parseSize разбирает размер в байтах с необязательным суффиксом K или M (степени 1024).
+func parseSize(s string) (int, error) {
+	mult := 1
+	switch {
+	case strings.HasSuffix(s, "K"), strings.HasSuffix(s, "k"):
+		mult = 1024
+	case strings.HasSuffix(s, "M"), strings.HasSuffix(s, "m"):
+		mult = 1024 * 1024
+	}
+	if mult != 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("bad size %q", s)
+	}
+	return n * mult, nil
+}
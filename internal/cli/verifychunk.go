@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+)
+
+type VerifyChunkOptions struct {
+	Root      string // доверенный корень "sha256-tree-1M:<chunks>:<hex>"
+	Chunk     int    // номер чанка, который запрашивал проверяющий
+	ProofPath string
+	InputPath string // байты одного чанка
+}
+
+func ParseVerifyChunkArgs(args []string) (*VerifyChunkOptions, error) {
+	fs := flag.NewFlagSet("verify-chunk", flag.ContinueOnError)
+
+	root := fs.String("root", "", "trusted sha256-tree digest of the whole file, e.g. sha256-tree-1M:<chunks>:<hex>")
+	chunk := fs.Int("chunk", -1, "zero-based number of the requested chunk; the proof must be for this chunk")
+	proof := fs.String("proof", "", "inclusion proof file from 'dgst --proof --chunk N'")
+	input := fs.String("input", "", "file with the downloaded chunk")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *root == "" || *proof == "" || *input == "" || *chunk < 0 {
+		return nil, fmt.Errorf("--root, --chunk N, --proof and --input are required")
+	}
+
+	return &VerifyChunkOptions{
+		Root:      *root,
+		Chunk:     *chunk,
+		ProofPath: *proof,
+		InputPath: *input,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package crypto
+
+import "fmt"
+
+// AEAD: общий интерфейс аутентифицированных режимов (AES-GCM, ChaCha20-Poly1305, ...).
+// Seal дописывает ciphertext||tag к dst; Open проверяет тег до расшифрования
+// и при несовпадении возвращает ErrAuthFailed.
+type AEAD interface {
+	NonceSize() int
+	Overhead() int
+	Seal(dst, nonce, plaintext, aad []byte) []byte
+	Open(dst, nonce, sealed, aad []byte) ([]byte, error)
+}
+
+// VectorAEAD: AEAD, принимающий AD как вектор строк (AES-SIV, RFC 5297, 2.4).
+// Каждый компонент, в том числе пустой, отдельно входит в вычисление тега,
+// поэтому склеивать компоненты в один aad нельзя.
+type VectorAEAD interface {
+	AEAD
+	SealVector(dst, nonce, plaintext []byte, ad [][]byte) []byte
+	OpenVector(dst, nonce, sealed []byte, ad [][]byte) ([]byte, error)
+}
+
+// NewAEAD возвращает AEAD для пары --algorithm/--mode.
+func NewAEAD(algorithm, mode string, key []byte) (AEAD, error) {
+	switch {
+	case IsChaChaAlgorithm(algorithm):
+		if mode != ModeAEAD {
+			return nil, fmt.Errorf("%s is an AEAD cipher and has no mode %q", algorithm, mode)
+		}
+		if algorithm == "xchacha20-poly1305" {
+			return NewXChaCha20Poly1305(key)
+		}
+		return NewChaCha20Poly1305(key)
+	case mode == "gcm":
+		block, err := NewBlockCipher(algorithm, key)
+		if err != nil {
+			return nil, err
+		}
+		return newGCM(block), nil
+	case mode == "siv":
+		if err := CheckAlgorithmMode(algorithm, mode); err != nil {
+			return nil, err
+		}
+		return NewSIV(key)
+	default:
+		return nil, fmt.Errorf("%s/%s is not an AEAD mode", algorithm, mode)
+	}
+}
+
+// ModeAEAD: единственный «режим» шифров, которые сами являются AEAD (ChaCha20-Poly1305).
+const ModeAEAD = "aead"
+
+// IsAEAD сообщает, аутентифицирует ли пара --algorithm/--mode данные.
+func IsAEAD(algorithm, mode string) bool {
+	return mode == "gcm" || mode == "siv" || (IsChaChaAlgorithm(algorithm) && mode == ModeAEAD)
+}
+
+func IsChaChaAlgorithm(algorithm string) bool {
+	return algorithm == "chacha20-poly1305" || algorithm == "xchacha20-poly1305"
+}
+
+// IVSize: длина IV/nonce, которую пара --algorithm/--mode хранит в файле.
+func IVSize(algorithm, mode string) int {
+	switch {
+	case algorithm == "xchacha20-poly1305":
+		return XChaCha20NonceSize
+	case IsChaChaAlgorithm(algorithm):
+		return ChaCha20NonceSize
+	case mode == "ecb", mode == "siv", mode == "xts":
+		return 0
+	case mode == "gcm":
+		return GCMNonceSize
+	default:
+		return BlockSize
+	}
+}
@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"cryptcore/internal/blockcipher"
+)
+
+// modeOutputs шифрует одни и те же данные всеми режимами текущей реализацией AES.
+func modeOutputs(t *testing.T) map[string][]byte {
+	t.Helper()
+	key := mustHex(t, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	iv := mustHex(t, "f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	out := map[string][]byte{}
+	for _, mode := range []string{"ecb", "cbc", "cfb", "cfb8", "cfb1", "ofb", "ctr"} {
+		var enc bytes.Buffer
+		w, err := NewEncryptWriter(mode, key, iv, &enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+		w.Close()
+		out[mode] = enc.Bytes()
+
+		var dec bytes.Buffer
+		r, _ := NewDecryptWriter(mode, key, iv, &dec)
+		r.Write(enc.Bytes())
+		if err := r.Close(); err != nil || !bytes.Equal(dec.Bytes(), data) {
+			t.Fatalf("%s: round trip failed: %v", mode, err)
+		}
+	}
+
+	var cts bytes.Buffer
+	w, _ := NewCTSWriter("aes-256", "cbc", PaddingCTSCS3, key, iv, false, &cts)
+	w.Write(data[:45])
+	w.Close()
+	out["cts"] = cts.Bytes()
+
+	for _, mode := range []string{"gcm", "siv"} {
+		k := key
+		if mode == "siv" {
+			k = append(append([]byte(nil), key...), key...)
+		}
+		aead, err := NewAEAD("aes-256", mode, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonce := iv[:aead.NonceSize()]
+		sealed := aead.Seal(nil, nonce, data, []byte("aad"))
+		if _, err := aead.Open(nil, nonce, sealed, []byte("aad")); err != nil {
+			t.Fatalf("%s: open failed: %v", mode, err)
+		}
+		out[mode] = sealed
+	}
+
+	x, err := NewXTS(key) // XTS-AES-128: K1 || K2
+	if err != nil {
+		t.Fatal(err)
+	}
+	sector := make([]byte, 100)
+	if err := x.EncryptSector(sector, data[:100], 7); err != nil {
+		t.Fatal(err)
+	}
+	out["xts"] = sector
+
+	wrapped, err := WrapKey(key, data[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	out["keywrap"] = wrapped
+	return out
+}
+
+func TestModes_AllAESImplementations(t *testing.T) {
+	defer blockcipher.SetAESImplementation(blockcipher.AESStd)
+
+	want := modeOutputs(t)
+	for _, impl := range blockcipher.AESImplementations() {
+		if err := blockcipher.SetAESImplementation(impl); err != nil {
+			t.Fatal(err)
+		}
+		got := modeOutputs(t)
+		for mode, ct := range want {
+			if !bytes.Equal(got[mode], ct) {
+				t.Fatalf("%s/%s: output differs from crypto/aes", impl, mode)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	ChaCha20KeySize    = 32
+	ChaCha20NonceSize  = 12
+	XChaCha20NonceSize = 24
+	chachaBlockSize    = 64
+)
+
+// "expand 32-byte k"
+var chachaConstants = [4]uint32{0x61707865, 0x3320646e, 0x79622d32, 0x6b206574}
+
+func chachaQuarterRound(a, b, c, d uint32) (uint32, uint32, uint32, uint32) {
+	a += b
+	d ^= a
+	d = bits.RotateLeft32(d, 16)
+	c += d
+	b ^= c
+	b = bits.RotateLeft32(b, 12)
+	a += b
+	d ^= a
+	d = bits.RotateLeft32(d, 8)
+	c += d
+	b ^= c
+	b = bits.RotateLeft32(b, 7)
+	return a, b, c, d
+}
+
+// chachaRounds: 20 раундов (10 двойных: по столбцам и по диагоналям).
+func chachaRounds(x *[16]uint32) {
+	for i := 0; i < 10; i++ {
+		x[0], x[4], x[8], x[12] = chachaQuarterRound(x[0], x[4], x[8], x[12])
+		x[1], x[5], x[9], x[13] = chachaQuarterRound(x[1], x[5], x[9], x[13])
+		x[2], x[6], x[10], x[14] = chachaQuarterRound(x[2], x[6], x[10], x[14])
+		x[3], x[7], x[11], x[15] = chachaQuarterRound(x[3], x[7], x[11], x[15])
+
+		x[0], x[5], x[10], x[15] = chachaQuarterRound(x[0], x[5], x[10], x[15])
+		x[1], x[6], x[11], x[12] = chachaQuarterRound(x[1], x[6], x[11], x[12])
+		x[2], x[7], x[8], x[13] = chachaQuarterRound(x[2], x[7], x[8], x[13])
+		x[3], x[4], x[9], x[14] = chachaQuarterRound(x[3], x[4], x[9], x[14])
+	}
+}
+
+// chachaInitState: константы || ключ || счётчик || 96-битный nonce (RFC 8439, 2.3).
+func chachaInitState(key []byte, counter uint32, nonce []byte) [16]uint32 {
+	var s [16]uint32
+	copy(s[:4], chachaConstants[:])
+	for i := 0; i < 8; i++ {
+		s[4+i] = binary.LittleEndian.Uint32(key[i*4:])
+	}
+	s[12] = counter
+	for i := 0; i < 3; i++ {
+		s[13+i] = binary.LittleEndian.Uint32(nonce[i*4:])
+	}
+	return s
+}
+
+// chachaBlock: блок ключевого потока = state + rounds(state).
+func chachaBlock(dst []byte, state *[16]uint32) {
+	x := *state
+	chachaRounds(&x)
+	for i := range x {
+		binary.LittleEndian.PutUint32(dst[i*4:], x[i]+state[i])
+	}
+}
+
+// hChaCha20: подключ для XChaCha20 из ключа и первых 16 байт nonce
+// (draft-irtf-cfrg-xchacha, 2.2): слова 0..3 и 12..15 после раундов, без сложения.
+func hChaCha20(key, nonce16 []byte) []byte {
+	var x [16]uint32
+	copy(x[:4], chachaConstants[:])
+	for i := 0; i < 8; i++ {
+		x[4+i] = binary.LittleEndian.Uint32(key[i*4:])
+	}
+	for i := 0; i < 4; i++ {
+		x[12+i] = binary.LittleEndian.Uint32(nonce16[i*4:])
+	}
+	chachaRounds(&x)
+
+	out := make([]byte, ChaCha20KeySize)
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint32(out[i*4:], x[i])
+		binary.LittleEndian.PutUint32(out[16+i*4:], x[12+i])
+	}
+	return out
+}
+
+// chachaStream: ключевой поток ChaCha20, реализует keyStream.
+type chachaStream struct {
+	state     [16]uint32
+	keystream [chachaBlockSize]byte
+	pos       int
+}
+
+func newChaChaStream(key, nonce []byte, counter uint32) *chachaStream {
+	return &chachaStream{state: chachaInitState(key, counter, nonce), pos: chachaBlockSize}
+}
+
+func (x *chachaStream) xorKeyStream(dst, src []byte) {
+	for i, c := range src {
+		if x.pos == chachaBlockSize {
+			chachaBlock(x.keystream[:], &x.state)
+			x.state[12]++
+			x.pos = 0
+		}
+		dst[i] = c ^ x.keystream[x.pos]
+		x.pos++
+	}
+}
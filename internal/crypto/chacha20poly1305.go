@@ -0,0 +1,146 @@
+package crypto
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+
+	"cryptcore/internal/mac"
+)
+
+const Poly1305TagSize = mac.Poly1305TagSize
+
+// chacha20Poly1305: AEAD из RFC 8439, 2.8.
+type chacha20Poly1305 struct {
+	key []byte
+}
+
+// xchacha20Poly1305: тот же AEAD с 192-битным nonce; подключ = HChaCha20(key, nonce[:16]),
+// nonce ChaCha20 = 0^32 || nonce[16:24].
+type xchacha20Poly1305 struct {
+	key []byte
+}
+
+func NewChaCha20Poly1305(key []byte) (AEAD, error) {
+	if len(key) != ChaCha20KeySize {
+		return nil, errors.New("ChaCha20-Poly1305 key must be 32 bytes")
+	}
+	return &chacha20Poly1305{key: append([]byte(nil), key...)}, nil
+}
+
+func NewXChaCha20Poly1305(key []byte) (AEAD, error) {
+	if len(key) != ChaCha20KeySize {
+		return nil, errors.New("XChaCha20-Poly1305 key must be 32 bytes")
+	}
+	return &xchacha20Poly1305{key: append([]byte(nil), key...)}, nil
+}
+
+func (c *chacha20Poly1305) NonceSize() int { return ChaCha20NonceSize }
+func (c *chacha20Poly1305) Overhead() int  { return Poly1305TagSize }
+
+func (c *chacha20Poly1305) Seal(dst, nonce, plaintext, aad []byte) []byte {
+	if len(nonce) != ChaCha20NonceSize {
+		panic("chacha20poly1305: bad nonce length")
+	}
+	return chachaPolySeal(dst, c.key, nonce, plaintext, aad)
+}
+
+func (c *chacha20Poly1305) Open(dst, nonce, sealed, aad []byte) ([]byte, error) {
+	if len(nonce) != ChaCha20NonceSize {
+		return nil, errors.New("ChaCha20-Poly1305 nonce must be 12 bytes")
+	}
+	return chachaPolyOpen(dst, c.key, nonce, sealed, aad)
+}
+
+func (c *xchacha20Poly1305) NonceSize() int { return XChaCha20NonceSize }
+func (c *xchacha20Poly1305) Overhead() int  { return Poly1305TagSize }
+
+func (c *xchacha20Poly1305) Seal(dst, nonce, plaintext, aad []byte) []byte {
+	if len(nonce) != XChaCha20NonceSize {
+		panic("xchacha20poly1305: bad nonce length")
+	}
+	subKey, subNonce := xchachaSubKey(c.key, nonce)
+	return chachaPolySeal(dst, subKey, subNonce, plaintext, aad)
+}
+
+func (c *xchacha20Poly1305) Open(dst, nonce, sealed, aad []byte) ([]byte, error) {
+	if len(nonce) != XChaCha20NonceSize {
+		return nil, errors.New("XChaCha20-Poly1305 nonce must be 24 bytes")
+	}
+	subKey, subNonce := xchachaSubKey(c.key, nonce)
+	return chachaPolyOpen(dst, subKey, subNonce, sealed, aad)
+}
+
+func xchachaSubKey(key, nonce []byte) ([]byte, []byte) {
+	subNonce := make([]byte, ChaCha20NonceSize)
+	copy(subNonce[4:], nonce[16:])
+	return hChaCha20(key, nonce[:16]), subNonce
+}
+
+func chachaPolySeal(dst, key, nonce, plaintext, aad []byte) []byte {
+	n := len(dst)
+	dst = append(dst, make([]byte, len(plaintext)+Poly1305TagSize)...)
+	ciphertext := dst[n : n+len(plaintext)]
+
+	// блок 0 — одноразовый ключ Poly1305, шифрование начинается со счётчика 1
+	newChaChaStream(key, nonce, 1).xorKeyStream(ciphertext, plaintext)
+	chachaPolyTag(dst[n+len(plaintext):], key, nonce, aad, ciphertext)
+	return dst
+}
+
+func chachaPolyOpen(dst, key, nonce, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < Poly1305TagSize {
+		return nil, ErrAuthFailed
+	}
+	ciphertext := sealed[:len(sealed)-Poly1305TagSize]
+	tag := sealed[len(sealed)-Poly1305TagSize:]
+
+	var expected [Poly1305TagSize]byte
+	chachaPolyTag(expected[:], key, nonce, aad, ciphertext)
+	if subtle.ConstantTimeCompare(expected[:], tag) != 1 {
+		return nil, ErrAuthFailed
+	}
+
+	n := len(dst)
+	dst = append(dst, make([]byte, len(ciphertext))...)
+	newChaChaStream(key, nonce, 1).xorKeyStream(dst[n:], ciphertext)
+	return dst, nil
+}
+
+// Poly1305KeyGen выводит одноразовый ключ Poly1305 из ключа ChaCha20 и nonce
+// (RFC 8439, 2.6): первые 32 байта блока ChaCha20 со счётчиком 0.
+func Poly1305KeyGen(key, nonce []byte) ([]byte, error) {
+	if len(key) != ChaCha20KeySize {
+		return nil, errors.New("poly1305 key generation needs a 32-byte key")
+	}
+	if len(nonce) != ChaCha20NonceSize {
+		return nil, errors.New("poly1305 key generation needs a 12-byte nonce")
+	}
+	return poly1305KeyGen(key, nonce), nil
+}
+
+func poly1305KeyGen(key, nonce []byte) []byte {
+	var block [chachaBlockSize]byte
+	state := chachaInitState(key, 0, nonce)
+	chachaBlock(block[:], &state)
+	return block[:mac.Poly1305KeySize]
+}
+
+// chachaPolyTag: Poly1305(otk, aad || pad16 || ciphertext || pad16 || len(aad) || len(ciphertext)).
+func chachaPolyTag(dst, key, nonce, aad, ciphertext []byte) {
+	p, _ := mac.NewPoly1305(poly1305KeyGen(key, nonce))
+	var zeros [16]byte
+	p.Write(aad)
+	if r := len(aad) % 16; r != 0 {
+		p.Write(zeros[:16-r])
+	}
+	p.Write(ciphertext)
+	if r := len(ciphertext) % 16; r != 0 {
+		p.Write(zeros[:16-r])
+	}
+	var lengths [16]byte
+	binary.LittleEndian.PutUint64(lengths[:8], uint64(len(aad)))
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(len(ciphertext)))
+	p.Write(lengths[:])
+	copy(dst, p.Sum(nil))
+}
@@ -0,0 +1,137 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+const sunscreen = "Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it."
+
+func TestChaCha20_Block_RFC8439(t *testing.T) {
+	// RFC 8439, 2.3.2
+	key := mustHex(t, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	nonce := mustHex(t, "000000090000004a00000000")
+	want := "10f1e7e4d13b5915500fdd1fa32071c4c7d1f4c733c068030422aa9ac3d46c4ed2826446079faa0914c2d705d98b02a2b5129cd1de164eb9cbd083e8a2503c4e"
+
+	state := chachaInitState(key, 1, nonce)
+	out := make([]byte, chachaBlockSize)
+	chachaBlock(out, &state)
+	if got := hex.EncodeToString(out); got != want {
+		t.Fatalf("block mismatch:\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestChaCha20_Encrypt_RFC8439(t *testing.T) {
+	// RFC 8439, 2.4.2
+	key := mustHex(t, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	nonce := mustHex(t, "000000000000004a00000000")
+	want := "6e2e359a2568f98041ba0728dd0d6981e97e7aec1d4360c20a27afccfd9fae0bf91b65c5524733ab8f593dabcd62b3571639d624e65152ab8f530c359f0861d807ca0dbf500d6a6156a38e088a22b65e52bc514d16ccf806818ce91ab77937365af90bbf74a35be6b40b8eedf2785e42874d"
+
+	out := make([]byte, len(sunscreen))
+	s := newChaChaStream(key, nonce, 1)
+	// две части: состояние должно переноситься между вызовами
+	s.xorKeyStream(out[:70], []byte(sunscreen)[:70])
+	s.xorKeyStream(out[70:], []byte(sunscreen)[70:])
+	if got := hex.EncodeToString(out); got != want {
+		t.Fatalf("ciphertext mismatch:\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestHChaCha20(t *testing.T) {
+	// draft-irtf-cfrg-xchacha, 2.2.1
+	key := mustHex(t, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	nonce := mustHex(t, "000000090000004a0000000031415927")
+	want := "82413b4227b27bfed30e42508a877d73a0f9e4d58a74a853c12ec41326d3ecdc"
+	if got := hex.EncodeToString(hChaCha20(key, nonce)); got != want {
+		t.Fatalf("hchacha20 mismatch:\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestPoly1305KeyGen_RFC8439(t *testing.T) {
+	// RFC 8439, 2.6.2
+	key := mustHex(t, "808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+	nonce := mustHex(t, "000000000001020304050607")
+	want := "8ad5a08b905f81cc815040274ab29471a833b637e3fd0da508dbb8e2fdd1a646"
+
+	otk, err := Poly1305KeyGen(key, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(otk); got != want {
+		t.Fatalf("one-time key mismatch:\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestChaCha20Poly1305_Vectors(t *testing.T) {
+	key := mustHex(t, "808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+	aad := mustHex(t, "50515253c0c1c2c3c4c5c6c7")
+
+	cases := []struct {
+		name       string
+		newAEAD    func([]byte) (AEAD, error)
+		nonce      string
+		ciphertext string
+		tag        string
+	}{
+		{
+			// RFC 8439, 2.8.2
+			name:       "chacha20-poly1305",
+			newAEAD:    NewChaCha20Poly1305,
+			nonce:      "070000004041424344454647",
+			ciphertext: "d31a8d34648e60db7b86afbc53ef7ec2a4aded51296e08fea9e2b5a736ee62d63dbea45e8ca9671282fafb69da92728b1a71de0a9e060b2905d6a5b67ecd3b3692ddbd7f2d778b8c9803aee328091b58fab324e4fad675945585808b4831d7bc3ff4def08e4b7a9de576d26586cec64b6116",
+			tag:        "1ae10b594f09e26a7e902ecbd0600691",
+		},
+		{
+			// draft-irtf-cfrg-xchacha, A.3.1
+			name:       "xchacha20-poly1305",
+			newAEAD:    NewXChaCha20Poly1305,
+			nonce:      "404142434445464748494a4b4c4d4e4f5051525354555657",
+			ciphertext: "bd6d179d3e83d43b9576579493c0e939572a1700252bfaccbed2902c21396cbb731c7f1b0b4aa6440bf3a82f4eda7e39ae64c6708c54c216cb96b72e1213b4522f8c9ba40db5d945b11b69b982c1bb9e3f3fac2bc369488f76b2383565d3fff921f9664c97637da9768812f615c68b13b52e",
+			tag:        "c0875924c1c7987947deafd8780acf49",
+		},
+	}
+
+	for _, c := range cases {
+		a, err := c.newAEAD(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonce := mustHex(t, c.nonce)
+
+		sealed := a.Seal(nil, nonce, []byte(sunscreen), aad)
+		if got := hex.EncodeToString(sealed); got != c.ciphertext+c.tag {
+			t.Fatalf("%s: seal mismatch:\ngot:  %s\nwant: %s", c.name, got, c.ciphertext+c.tag)
+		}
+
+		opened, err := a.Open(nil, nonce, sealed, aad)
+		if err != nil || string(opened) != sunscreen {
+			t.Fatalf("%s: open failed: %v", c.name, err)
+		}
+
+		sealed[0] ^= 0x80
+		if _, err := a.Open(nil, nonce, sealed, aad); !errors.Is(err, ErrAuthFailed) {
+			t.Fatalf("%s: expected ErrAuthFailed, got %v", c.name, err)
+		}
+	}
+}
+
+func TestNewAEAD(t *testing.T) {
+	key := bytes.Repeat([]byte{0x01}, 32)
+	for _, alg := range []string{"chacha20-poly1305", "xchacha20-poly1305"} {
+		a, err := NewAEAD(alg, ModeAEAD, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.NonceSize() != IVSize(alg, ModeAEAD) {
+			t.Fatalf("%s: nonce size %d does not match IVSize", alg, a.NonceSize())
+		}
+	}
+	if _, err := NewAEAD("aes-256", "gcm", key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAEAD("aes-256", "cbc", key); err == nil {
+		t.Fatalf("expected error for non-AEAD mode")
+	}
+}
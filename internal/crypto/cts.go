@@ -0,0 +1,189 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Кража шифртекста (NIST SP 800-38A Addendum, CBC-CS1/CS2/CS3) вместо дополнения:
+// длина шифртекста равна длине открытого текста, вход — не короче одного блока.
+// Последний неполный блок Pn* (d байт) шифруется, дополненный «украденным» хвостом
+// предпоследнего блока шифртекста; от предпоследнего блока остаётся C*n-1 = первые d байт.
+//
+// Варианты различаются только порядком двух последних блоков:
+//
+//	CS1: ... C*n-1 || Cn
+//	CS2: как CS1, если последний блок полный, иначе ... Cn || C*n-1
+//	CS3: всегда ... Cn || C*n-1 (Kerberos, RFC 3962)
+//
+// Для ECB используется та же схема без сцепления блоков.
+
+// ErrCTSShortInput: для кражи шифртекста нужен хотя бы один полный блок.
+var ErrCTSShortInput = errors.New("ciphertext stealing requires at least 16 bytes of input")
+
+// IsCTS сообщает, является ли --padding вариантом кражи шифртекста.
+func IsCTS(padding string) bool {
+	return padding == PaddingCTSCS1 || padding == PaddingCTSCS2 || padding == PaddingCTSCS3
+}
+
+// NewCTSWriter: потоковое ECB/CBC с кражей шифртекста. Два последних блока
+// удерживаются до Close.
+func NewCTSWriter(algorithm, mode, variant string, key, iv []byte, decrypt bool, w io.Writer) (io.WriteCloser, error) {
+	if !IsCTS(variant) {
+		return nil, fmt.Errorf("unknown ciphertext stealing variant %q", variant)
+	}
+	block, err := NewBlockCipher(algorithm, key)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &ctsWriter{block: block, variant: variant, decrypt: decrypt, w: w}
+	switch mode {
+	case "ecb":
+		if decrypt {
+			x.chain = ecbDecrypter{block}
+		} else {
+			x.chain = ecbEncrypter{block}
+		}
+	case "cbc":
+		if len(iv) != BlockSize {
+			return nil, errors.New("IV must be 16 bytes")
+		}
+		x.cbc = true
+		if decrypt {
+			x.chain = newCBCDecrypter(block, iv)
+		} else {
+			x.chain = newCBCEncrypter(block, iv)
+		}
+	default:
+		return nil, fmt.Errorf("ciphertext stealing is only supported for ecb and cbc, not %s", mode)
+	}
+	return x, nil
+}
+
+type ctsWriter struct {
+	block   cipherBlock
+	chain   blockMode // ECB/CBC для всех блоков, кроме двух последних
+	cbc     bool
+	variant string
+	decrypt bool
+	w       io.Writer
+	buf     []byte
+	out     []byte
+	total   int
+	closed  bool
+}
+
+func (x *ctsWriter) Write(p []byte) (int, error) {
+	if x.closed {
+		return 0, errors.New("write to closed cipher stream")
+	}
+	x.buf = append(x.buf, p...)
+
+	// в буфере остаётся от 17 до 32 байт: хвост и блок, у которого он «крадёт»
+	if len(x.buf) <= 2*BlockSize {
+		return len(p), nil
+	}
+	n := (len(x.buf) - BlockSize - 1) / BlockSize * BlockSize
+	if cap(x.out) < n {
+		x.out = make([]byte, n)
+	}
+	out := x.out[:n]
+	x.chain.cryptBlocks(out, x.buf[:n])
+	rest := copy(x.buf, x.buf[n:])
+	x.buf = x.buf[:rest]
+	x.total += n
+
+	if _, err := x.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (x *ctsWriter) Close() error {
+	if x.closed {
+		return nil
+	}
+	x.closed = true
+
+	if x.total+len(x.buf) < BlockSize {
+		return ErrCTSShortInput
+	}
+	if len(x.buf) == BlockSize {
+		// весь вход — один блок: красть не у кого
+		out := make([]byte, BlockSize)
+		x.chain.cryptBlocks(out, x.buf)
+		_, err := x.w.Write(out)
+		return err
+	}
+
+	var out []byte
+	if x.decrypt {
+		out = x.decryptTail(x.buf)
+	} else {
+		out = x.encryptTail(x.buf)
+	}
+	_, err := x.w.Write(out)
+	return err
+}
+
+// swapped: идут ли два последних блока в порядке Cn || C*n-1.
+func (x *ctsWriter) swapped(d int) bool {
+	return x.variant == PaddingCTSCS3 || (x.variant == PaddingCTSCS2 && d < BlockSize)
+}
+
+// encryptTail: t = Pn-1 || Pn*, 16 < len(t) <= 32.
+func (x *ctsWriter) encryptTail(t []byte) []byte {
+	d := len(t) - BlockSize
+	var cPrev, cLast [BlockSize]byte // Cn-1 (полный) и Cn
+
+	if x.cbc {
+		// Cn-1 = CBC(Pn-1), Cn = E(Cn-1 xor (Pn* || 0)) — обычный CBC над дополненным нулями хвостом
+		var two [2 * BlockSize]byte
+		copy(two[:], t)
+		x.chain.cryptBlocks(two[:], two[:])
+		copy(cPrev[:], two[:BlockSize])
+		copy(cLast[:], two[BlockSize:])
+	} else {
+		// X = E(Pn-1), Cn = E(Pn* || X[d:])
+		x.chain.cryptBlocks(cPrev[:], t[:BlockSize])
+		copy(cLast[:], t[BlockSize:])
+		copy(cLast[d:], cPrev[d:])
+		x.block.Encrypt(cLast[:], cLast[:])
+	}
+
+	out := make([]byte, 0, len(t))
+	if x.swapped(d) {
+		out = append(out, cLast[:]...)
+		return append(out, cPrev[:d]...)
+	}
+	out = append(out, cPrev[:d]...)
+	return append(out, cLast[:]...)
+}
+
+// decryptTail: обратная операция для двух последних блоков в порядке варианта.
+func (x *ctsWriter) decryptTail(t []byte) []byte {
+	d := len(t) - BlockSize
+	var cStar, cLast []byte
+	if x.swapped(d) {
+		cLast, cStar = t[:BlockSize], t[BlockSize:]
+	} else {
+		cStar, cLast = t[:d], t[d:]
+	}
+
+	// Z = D(Cn): в CBC это Cn-1 xor (Pn* || 0), в ECB — Pn* || X[d:]
+	var z, cPrev [BlockSize]byte
+	x.block.Decrypt(z[:], cLast)
+	copy(cPrev[:], cStar)
+	copy(cPrev[d:], z[d:])
+
+	out := make([]byte, len(t))
+	x.chain.cryptBlocks(out[:BlockSize], cPrev[:])
+	if x.cbc {
+		xorBlocks(out[BlockSize:], z[:d], cStar)
+	} else {
+		copy(out[BlockSize:], z[:d])
+	}
+	return out
+}
@@ -1,12 +1,13 @@
 package crypto
 
 import (
-	"crypto/aes"
 	"errors"
+
+	"cryptcore/internal/blockcipher"
 )
 
 func EncryptECB(key, plaintext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
+	block, err := blockcipher.NewAES(key)
 	if err != nil {
 		return nil, err
 	}
@@ -20,15 +21,12 @@ func EncryptECB(key, plaintext []byte) ([]byte, error) {
 	}
 
 	out := make([]byte, len(padded))
-	for bs := 0; bs < len(padded); bs += BlockSize {
-		be := bs + BlockSize
-		block.Encrypt(out[bs:be], padded[bs:be])
-	}
+	ecbEncrypter{block}.cryptBlocks(out, padded)
 	return out, nil
 }
 
 func DecryptECB(key, ciphertext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
+	block, err := blockcipher.NewAES(key)
 	if err != nil {
 		return nil, err
 	}
@@ -37,10 +35,7 @@ func DecryptECB(key, ciphertext []byte) ([]byte, error) {
 	}
 
 	out := make([]byte, len(ciphertext))
-	for bs := 0; bs < len(ciphertext); bs += BlockSize {
-		be := bs + BlockSize
-		block.Decrypt(out[bs:be], ciphertext[bs:be])
-	}
+	ecbDecrypter{block}.cryptBlocks(out, ciphertext)
 
 	return PKCS7Unpad(out, BlockSize)
 }
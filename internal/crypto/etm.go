@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"hash"
+	"io"
+
+	myhash "cryptcore/internal/hash"
+	"cryptcore/internal/kdf"
+	"cryptcore/internal/mac"
+)
+
+// Encrypt-then-MAC для cbc/cfb/ofb/ctr: тег HMAC над header||IV||ciphertext
+// дописывается в конец файла и проверяется до расшифрования.
+// Ключ пользователя делится через kdf.DeriveKey на ключ шифрования и ключ MAC.
+const (
+	etmEncryptionContext     = "cryptocore/etm/encryption"
+	etmAuthenticationContext = "cryptocore/etm/authentication"
+)
+
+// etmMACIDs: id алгоритма MAC в параметре заголовка HeaderParamMAC.
+var etmMACIDs = map[string]byte{"hmac-sha256": 1, "hmac-sha512": 2}
+
+// IsEtMMAC сообщает, допустимо ли имя в --mac.
+func IsEtMMAC(name string) bool {
+	_, ok := etmMACIDs[name]
+	return ok
+}
+
+// EtMMACID / EtMMACName переводят имя MAC в значение параметра заголовка и обратно.
+func EtMMACID(name string) (byte, error) {
+	id, ok := etmMACIDs[name]
+	if !ok {
+		return 0, fmt.Errorf("unsupported MAC %q (hmac-sha256, hmac-sha512)", name)
+	}
+	return id, nil
+}
+
+func EtMMACName(id byte) (string, error) {
+	return nameByID(etmMACIDs, id, "mac")
+}
+
+// SplitEtMKeys выводит из ключа пользователя независимые ключи шифрования и MAC.
+// Длина ключа MAC равна размеру выхода хеш-функции.
+func SplitEtMKeys(masterKey []byte, encKeySize int, macName string) (encKey, macKey []byte, err error) {
+	h, err := etmHash(macName)
+	if err != nil {
+		return nil, nil, err
+	}
+	encKey = kdf.DeriveKey(masterKey, etmEncryptionContext, encKeySize)
+	macKey = kdf.DeriveKey(masterKey, etmAuthenticationContext, h().Size())
+	return encKey, macKey, nil
+}
+
+// NewEtMMAC возвращает HMAC для --mac.
+func NewEtMMAC(macName string, macKey []byte) (hash.Hash, error) {
+	h, err := etmHash(macName)
+	if err != nil {
+		return nil, err
+	}
+	return mac.New(h, macKey), nil
+}
+
+func etmHash(macName string) (func() hash.Hash, error) {
+	switch macName {
+	case "hmac-sha256":
+		return func() hash.Hash { return myhash.NewSHA256() }, nil
+	case "hmac-sha512":
+		return func() hash.Hash { return myhash.NewSHA512() }, nil
+	default:
+		return nil, fmt.Errorf("unsupported MAC %q (hmac-sha256, hmac-sha512)", macName)
+	}
+}
+
+// VerifyEtM дочитывает r до конца: всё, кроме последних m.Size() байт, подаётся в m
+// и копируется в w, хвост сравнивается с тегом за постоянное время. Возвращает длину
+// шифртекста без тега. m должен уже содержать header||IV. Расшифровывать нужно именно
+// записанное в w: повторное чтение r может вернуть уже другие данные.
+func VerifyEtM(m hash.Hash, r io.Reader, w io.Writer) (int64, error) {
+	tagSize := m.Size()
+	buf := make([]byte, 64*1024+tagSize)
+	held := 0 // байты в начале buf, которые ещё могут оказаться тегом
+	var total int64
+
+	for {
+		n, err := r.Read(buf[held:])
+		held += n
+		if held > tagSize {
+			m.Write(buf[:held-tagSize])
+			if _, err := w.Write(buf[:held-tagSize]); err != nil {
+				return 0, err
+			}
+			total += int64(held - tagSize)
+			held = copy(buf, buf[held-tagSize:held])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if held < tagSize {
+		return 0, fmt.Errorf("file too short to contain MAC tag: %w", ErrAuthFailed)
+	}
+	if subtle.ConstantTimeCompare(m.Sum(nil), buf[:tagSize]) != 1 {
+		return 0, ErrAuthFailed
+	}
+	return total, nil
+}
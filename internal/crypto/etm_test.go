@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestSplitEtMKeys(t *testing.T) {
+	master := bytes.Repeat([]byte{0x5a}, 16)
+	for _, name := range []string{"hmac-sha256", "hmac-sha512"} {
+		encKey, macKey, err := SplitEtMKeys(master, 16, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, _ := NewEtMMAC(name, macKey)
+		if len(encKey) != 16 || len(macKey) != m.Size() {
+			t.Fatalf("%s: unexpected key lengths %d/%d", name, len(encKey), len(macKey))
+		}
+		if bytes.Equal(encKey, macKey[:16]) || bytes.Equal(encKey, master) {
+			t.Fatalf("%s: keys are not separated", name)
+		}
+	}
+	if _, _, err := SplitEtMKeys(master, 16, "md5"); err == nil {
+		t.Fatalf("expected error for unsupported MAC")
+	}
+}
+
+func TestVerifyEtM(t *testing.T) {
+	master := bytes.Repeat([]byte{0x5a}, 32)
+	header := []byte("header||iv")
+
+	for _, size := range []int{0, 1, 63, 64, 65, 200000} {
+		encKey, macKey, _ := SplitEtMKeys(master, 32, "hmac-sha256")
+
+		var body bytes.Buffer
+		m, _ := NewEtMMAC("hmac-sha256", macKey)
+		m.Write(header)
+		w, _ := NewEncryptWriter("ctr", encKey, make([]byte, BlockSize), &body)
+		w.Write(bytes.Repeat([]byte{0x33}, size))
+		w.Close()
+		m.Write(body.Bytes())
+		file := append(body.Bytes(), m.Sum(nil)...)
+
+		v, _ := NewEtMMAC("hmac-sha256", macKey)
+		v.Write(header)
+		var verified bytes.Buffer
+		n, err := VerifyEtM(v, bytes.NewReader(file), &verified)
+		if err != nil || n != int64(size) {
+			t.Fatalf("size %d: verify failed: n=%d err=%v", size, n, err)
+		}
+		if !bytes.Equal(verified.Bytes(), body.Bytes()) {
+			t.Fatalf("size %d: verified ciphertext differs from the body", size)
+		}
+
+		for _, pos := range []int{0, len(file) / 2, len(file) - 1} {
+			tampered := append([]byte(nil), file...)
+			tampered[pos] ^= 0x01
+			v, _ := NewEtMMAC("hmac-sha256", macKey)
+			v.Write(header)
+			if _, err := VerifyEtM(v, bytes.NewReader(tampered), io.Discard); !errors.Is(err, ErrAuthFailed) {
+				t.Fatalf("size %d: flip at %d not detected: %v", size, pos, err)
+			}
+		}
+	}
+}
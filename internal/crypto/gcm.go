@@ -0,0 +1,158 @@
+package crypto
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"cryptcore/internal/blockcipher"
+	"cryptcore/internal/mac"
+)
+
+const (
+	GCMNonceSize = 12
+	GCMTagSize   = 16
+)
+
+// ErrAuthFailed возвращается, если тег не совпал. Открытый текст при этом не выдаётся.
+var ErrAuthFailed = errors.New("authentication failed: ciphertext or associated data was modified")
+
+// EncryptGCM: AES-GCM (NIST SP 800-38D) со случайным 96-битным nonce.
+// Формат файла: <12-байтный nonce><ciphertext><16-байтный tag>.
+func EncryptGCM(key, plaintext, aad []byte) ([]byte, error) {
+	nonce, err := GenerateRandomBytes(GCMNonceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := SealGCM(key, nonce, plaintext, aad)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, sealed...), nil
+}
+
+// DecryptGCM: проверяет тег и только после этого расшифровывает.
+// При несовпадении тега возвращает ErrAuthFailed.
+func DecryptGCM(key, input, aad []byte) ([]byte, error) {
+	if len(input) < GCMNonceSize+GCMTagSize {
+		return nil, errors.New("ciphertext file too short to contain nonce and tag")
+	}
+	return OpenGCM(key, input[:GCMNonceSize], input[GCMNonceSize:], aad)
+}
+
+// SealGCM: шифрование с заданным nonce, возвращает ciphertext||tag.
+func SealGCM(key, nonce, plaintext, aad []byte) ([]byte, error) {
+	block, err := blockcipher.NewAES(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) == 0 {
+		return nil, errors.New("GCM nonce must not be empty")
+	}
+	return newGCM(block).Seal(nil, nonce, plaintext, aad), nil
+}
+
+// OpenGCM: обратная операция к SealGCM; sealed = ciphertext||tag.
+func OpenGCM(key, nonce, sealed, aad []byte) ([]byte, error) {
+	block, err := blockcipher.NewAES(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) == 0 {
+		return nil, errors.New("GCM nonce must not be empty")
+	}
+	return newGCM(block).Open(nil, nonce, sealed, aad)
+}
+
+type gcm struct {
+	block cipherBlock
+	h     []byte // hash subkey H = E(K, 0^128)
+}
+
+func newGCM(block cipherBlock) *gcm {
+	h := make([]byte, BlockSize)
+	block.Encrypt(h, h)
+	return &gcm{block: block, h: h}
+}
+
+func (g *gcm) NonceSize() int { return GCMNonceSize }
+func (g *gcm) Overhead() int  { return GCMTagSize }
+
+// Seal дописывает ciphertext||tag к dst.
+func (g *gcm) Seal(dst, nonce, plaintext, aad []byte) []byte {
+	j0 := g.deriveJ0(nonce)
+
+	n := len(dst)
+	dst = append(dst, make([]byte, len(plaintext)+GCMTagSize)...)
+	ciphertext := dst[n : n+len(plaintext)]
+	g.counterStream(j0).xorKeyStream(ciphertext, plaintext)
+
+	g.tag(dst[n+len(plaintext):], j0, aad, ciphertext)
+	return dst
+}
+
+// Open проверяет тег за постоянное время и лишь затем расшифровывает.
+func (g *gcm) Open(dst, nonce, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < GCMTagSize {
+		return nil, ErrAuthFailed
+	}
+	ciphertext := sealed[:len(sealed)-GCMTagSize]
+	tag := sealed[len(sealed)-GCMTagSize:]
+
+	j0 := g.deriveJ0(nonce)
+
+	var expected [GCMTagSize]byte
+	g.tag(expected[:], j0, aad, ciphertext)
+	if subtle.ConstantTimeCompare(expected[:], tag) != 1 {
+		return nil, ErrAuthFailed
+	}
+
+	n := len(dst)
+	dst = append(dst, make([]byte, len(ciphertext))...)
+	g.counterStream(j0).xorKeyStream(dst[n:], ciphertext)
+	return dst, nil
+}
+
+// counterStream: CTR с inc32, начиная с inc32(J0).
+func (g *gcm) counterStream(j0 []byte) *ctrStream {
+	counter := make([]byte, BlockSize)
+	copy(counter, j0)
+	incrementCounter32(counter)
+	return newCTRStream(g.block, counter, incrementCounter32)
+}
+
+// deriveJ0: для 96-битного nonce J0 = nonce || 0^31 || 1, иначе J0 = GHASH(nonce).
+func (g *gcm) deriveJ0(nonce []byte) []byte {
+	j0 := make([]byte, BlockSize)
+	if len(nonce) == GCMNonceSize {
+		copy(j0, nonce)
+		j0[BlockSize-1] = 1
+		return j0
+	}
+
+	y := mac.NewGHASH(g.h)
+	y.Update(nonce)
+	y.UpdateLengths(0, uint64(len(nonce))*8)
+	y.Digest(j0)
+	return j0
+}
+
+// tag: T = E(K, J0) xor GHASH(A || C || len(A) || len(C)).
+func (g *gcm) tag(dst, j0, aad, ciphertext []byte) {
+	y := mac.NewGHASH(g.h)
+	y.Update(aad)
+	y.Update(ciphertext)
+	y.UpdateLengths(uint64(len(aad))*8, uint64(len(ciphertext))*8)
+
+	var s [BlockSize]byte
+	y.Digest(s[:])
+
+	ekj0 := make([]byte, BlockSize)
+	g.block.Encrypt(ekj0, j0)
+	xorBlocks(dst, s[:], ekj0)
+}
+
+// incrementCounter32: inc32 из SP 800-38D — инкремент только младших 32 бит.
+func incrementCounter32(c []byte) {
+	incrementCounter(c[len(c)-4:])
+}
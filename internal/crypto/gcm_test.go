@@ -0,0 +1,125 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", s, err)
+	}
+	return b
+}
+
+// NIST GCM test vectors (McGrew & Viega, "The Galois/Counter Mode of Operation"), AES-128 cases 1-6.
+var gcmVectors = []struct {
+	key, nonce, plaintext, aad, ciphertext, tag string
+}{
+	{
+		key:   "00000000000000000000000000000000",
+		nonce: "000000000000000000000000",
+		tag:   "58e2fccefa7e3061367f1d57a4e7455a",
+	},
+	{
+		key:        "00000000000000000000000000000000",
+		nonce:      "000000000000000000000000",
+		plaintext:  "00000000000000000000000000000000",
+		ciphertext: "0388dace60b6a392f328c2b971b2fe78",
+		tag:        "ab6e47d42cec13bdf53a67b21257bddf",
+	},
+	{
+		key:        "feffe9928665731c6d6a8f9467308308",
+		nonce:      "cafebabefacedbaddecaf888",
+		plaintext:  "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b391aafd255",
+		ciphertext: "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091473f5985",
+		tag:        "4d5c2af327cd64a62cf35abd2ba6fab4",
+	},
+	{
+		key:        "feffe9928665731c6d6a8f9467308308",
+		nonce:      "cafebabefacedbaddecaf888",
+		plaintext:  "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+		aad:        "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+		ciphertext: "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091",
+		tag:        "5bc94fbc3221a5db94fae95ae7121a47",
+	},
+	{
+		key:        "feffe9928665731c6d6a8f9467308308",
+		nonce:      "cafebabefacedbad",
+		plaintext:  "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+		aad:        "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+		ciphertext: "61353b4c2806934a777ff51fa22a4755699b2a714fcdc6f83766e5f97b6c742373806900e49f24b22b097544d4896b424989b5e1ebac0f07c23f4598",
+		tag:        "3612d2e79e3b0785561be14aaca2fccb",
+	},
+	{
+		key:        "feffe9928665731c6d6a8f9467308308",
+		nonce:      "9313225df88406e555909c5aff5269aa6a7a9538534f7da1e4c303d2a318a728c3c0c95156809539fcf0e2429a6b525416aedbf5a0de6a57a637b39b",
+		plaintext:  "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+		aad:        "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+		ciphertext: "8ce24998625615b603a033aca13fb894be9112a5c3a211a8ba262a3cca7e2ca701e4a9a4fba43c90ccdcb281d48c7c6fd62875d2aca417034c34aee5",
+		tag:        "619cc5aefffe0bfa462af43c1699d050",
+	},
+}
+
+func TestGCM_NISTVectors(t *testing.T) {
+	for i, v := range gcmVectors {
+		block, err := aes.NewCipher(mustHex(t, v.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := newGCM(block)
+		nonce := mustHex(t, v.nonce)
+		aad := mustHex(t, v.aad)
+
+		sealed := g.Seal(nil, nonce, mustHex(t, v.plaintext), aad)
+		want := v.ciphertext + v.tag
+		if got := hex.EncodeToString(sealed); got != want {
+			t.Fatalf("case %d: seal mismatch:\ngot:  %s\nwant: %s", i+1, got, want)
+		}
+
+		opened, err := g.Open(nil, nonce, sealed, aad)
+		if err != nil {
+			t.Fatalf("case %d: open failed: %v", i+1, err)
+		}
+		if hex.EncodeToString(opened) != v.plaintext {
+			t.Fatalf("case %d: open mismatch: got %x", i+1, opened)
+		}
+	}
+}
+
+func TestGCM_RoundTripAndTamper(t *testing.T) {
+	key := mustHex(t, "000102030405060708090a0b0c0d0e0f")
+	plaintext := []byte("authenticated message")
+	aad := []byte("header")
+
+	enc, err := EncryptGCM(key, plaintext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(enc) != GCMNonceSize+len(plaintext)+GCMTagSize {
+		t.Fatalf("unexpected ciphertext length %d", len(enc))
+	}
+
+	dec, err := DecryptGCM(key, enc, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dec, plaintext) {
+		t.Fatalf("round trip mismatch: got %q", dec)
+	}
+
+	tampered := append([]byte(nil), enc...)
+	tampered[GCMNonceSize] ^= 0x01
+	if out, err := DecryptGCM(key, tampered, aad); !errors.Is(err, ErrAuthFailed) || out != nil {
+		t.Fatalf("expected ErrAuthFailed for flipped bit, got %v", err)
+	}
+
+	if _, err := DecryptGCM(key, enc, []byte("other")); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("expected ErrAuthFailed for wrong AAD, got %v", err)
+	}
+}
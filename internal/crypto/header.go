@@ -0,0 +1,235 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Формат контейнера .enc (версия 1), все числа big-endian:
+//
+//	magic      4  "CCRY"
+//	version    1  HeaderVersion
+//	cipher     1  id шифра (aes-128/192/256, chacha20-poly1305, xchacha20-poly1305,
+//	              camellia-*, aria-*, serpent-*, twofish-*, sm4)
+//	mode       1  id режима (ecb, cbc, cfb, cfb8, cfb1, ofb, ctr, gcm, aead, siv, xts)
+//	kdf        1  id KDF (0 — сырой ключ, 1 — PBKDF2-HMAC-SHA256)
+//	iterations 4  число итераций KDF (0 для сырого ключа)
+//	saltLen    1  + salt
+//	ivLen      1  + IV/nonce
+//	paramCount 1  + параметры: id(1) len(1) value(len)
+//
+// Дальше идёт шифртекст выбранного режима. Для AEAD-режимов весь заголовок
+// входит в associated data, поэтому его подмена обнаруживается.
+const (
+	HeaderMagic   = "CCRY"
+	HeaderVersion = 1
+)
+
+const (
+	KDFNone         = ""
+	KDFPBKDF2SHA256 = "pbkdf2-sha256"
+	maxHeaderField  = 255
+)
+
+// id записываются в файл — существующие значения менять нельзя, только добавлять новые.
+var (
+	headerCipherIDs = map[string]byte{
+		"aes-128":            1,
+		"aes-192":            2,
+		"aes-256":            3,
+		"chacha20-poly1305":  4,
+		"xchacha20-poly1305": 5,
+		"camellia-128":       6,
+		"camellia-192":       7,
+		"camellia-256":       8,
+		"aria-128":           9,
+		"aria-192":           10,
+		"aria-256":           11,
+		"serpent-128":        12,
+		"serpent-192":        13,
+		"serpent-256":        14,
+		"twofish-128":        15,
+		"twofish-192":        16,
+		"twofish-256":        17,
+		"sm4":                18,
+	}
+	headerModeIDs = map[string]byte{"ecb": 1, "cbc": 2, "cfb": 3, "ofb": 4, "ctr": 5, "gcm": 6, ModeAEAD: 7, "siv": 8, "xts": 9, "cfb8": 10, "cfb1": 11}
+	headerKDFIDs  = map[string]byte{KDFNone: 0, KDFPBKDF2SHA256: 1}
+
+	// headerParamNames: известные id параметров; неизвестный параметр — ошибка чтения.
+	headerParamNames = map[byte]string{
+		HeaderParamChunkSize:  "chunk-size",
+		HeaderParamMAC:        "mac",
+		HeaderParamSectorSize: "sector-size",
+		HeaderParamPadding:    "padding",
+	}
+)
+
+// Параметры заголовка.
+const (
+	// HeaderParamChunkSize: размер чанка сегментированного AEAD (uint32); его наличие
+	// означает формат STREAM, а поле IV хранит prefix nonce чанков.
+	HeaderParamChunkSize byte = 1
+	// HeaderParamMAC: id HMAC для encrypt-then-MAC (1 байт); тег дописан в конец файла.
+	HeaderParamMAC byte = 2
+	// HeaderParamSectorSize: размер сектора XTS (uint32), обязателен для режима xts.
+	HeaderParamSectorSize byte = 3
+	// HeaderParamPadding: id способа выравнивания ecb/cbc (1 байт); отсутствует для PKCS#7.
+	HeaderParamPadding byte = 4
+)
+
+// ErrNoHeader: файл не начинается с magic — вероятно, старый формат без заголовка.
+var ErrNoHeader = errors.New("not a cryptocore container (missing header); use --legacy for headerless files")
+
+// Header описывает всё, что нужно для расшифрования, кроме ключа или пароля.
+type Header struct {
+	Cipher     string
+	Mode       string
+	KDF        string
+	Iterations uint32
+	Salt       []byte
+	IV         []byte
+	Params     []HeaderParam
+}
+
+// HeaderParam: дополнительный параметр режима (id из диапазона, известного этой версии).
+type HeaderParam struct {
+	ID    byte
+	Value []byte
+}
+
+// CanonicalCipher приводит алиасы к имени, записываемому в заголовок ("aes" -> "aes-128").
+func CanonicalCipher(algorithm string) string {
+	if algorithm == "aes" {
+		return "aes-128"
+	}
+	return algorithm
+}
+
+// Marshal сериализует заголовок. Результат детерминирован и используется как AAD.
+func (h *Header) Marshal() ([]byte, error) {
+	cipherID, ok := headerCipherIDs[h.Cipher]
+	if !ok {
+		return nil, fmt.Errorf("cannot store cipher %q in header", h.Cipher)
+	}
+	modeID, ok := headerModeIDs[h.Mode]
+	if !ok {
+		return nil, fmt.Errorf("cannot store mode %q in header", h.Mode)
+	}
+	kdfID, ok := headerKDFIDs[h.KDF]
+	if !ok {
+		return nil, fmt.Errorf("cannot store kdf %q in header", h.KDF)
+	}
+	if len(h.Salt) > maxHeaderField || len(h.IV) > maxHeaderField || len(h.Params) > maxHeaderField {
+		return nil, errors.New("header field too long")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(HeaderMagic)
+	buf.WriteByte(HeaderVersion)
+	buf.WriteByte(cipherID)
+	buf.WriteByte(modeID)
+	buf.WriteByte(kdfID)
+	binary.Write(&buf, binary.BigEndian, h.Iterations)
+	buf.WriteByte(byte(len(h.Salt)))
+	buf.Write(h.Salt)
+	buf.WriteByte(byte(len(h.IV)))
+	buf.Write(h.IV)
+	buf.WriteByte(byte(len(h.Params)))
+	for _, p := range h.Params {
+		if len(p.Value) > maxHeaderField {
+			return nil, errors.New("header parameter too long")
+		}
+		buf.WriteByte(p.ID)
+		buf.WriteByte(byte(len(p.Value)))
+		buf.Write(p.Value)
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadHeader читает заголовок из начала потока, оставляя r на первом байте шифртекста.
+func ReadHeader(r io.Reader) (*Header, error) {
+	var fixed [12]byte
+	if _, err := io.ReadFull(r, fixed[:4]); err != nil || string(fixed[:4]) != HeaderMagic {
+		return nil, ErrNoHeader
+	}
+	if _, err := io.ReadFull(r, fixed[4:]); err != nil {
+		return nil, errors.New("truncated header")
+	}
+	if fixed[4] != HeaderVersion {
+		return nil, fmt.Errorf("unsupported container version %d", fixed[4])
+	}
+
+	h := &Header{Iterations: binary.BigEndian.Uint32(fixed[8:12])}
+	var err error
+	if h.Cipher, err = nameByID(headerCipherIDs, fixed[5], "cipher"); err != nil {
+		return nil, err
+	}
+	if h.Mode, err = nameByID(headerModeIDs, fixed[6], "mode"); err != nil {
+		return nil, err
+	}
+	if h.KDF, err = nameByID(headerKDFIDs, fixed[7], "kdf"); err != nil {
+		return nil, err
+	}
+
+	if h.Salt, err = readShortField(r); err != nil {
+		return nil, err
+	}
+	if h.IV, err = readShortField(r); err != nil {
+		return nil, err
+	}
+
+	var count [1]byte
+	if _, err := io.ReadFull(r, count[:]); err != nil {
+		return nil, errors.New("truncated header")
+	}
+	for i := 0; i < int(count[0]); i++ {
+		var id [1]byte
+		if _, err := io.ReadFull(r, id[:]); err != nil {
+			return nil, errors.New("truncated header")
+		}
+		if _, ok := headerParamNames[id[0]]; !ok {
+			return nil, fmt.Errorf("unsupported header parameter id %d", id[0])
+		}
+		value, err := readShortField(r)
+		if err != nil {
+			return nil, err
+		}
+		h.Params = append(h.Params, HeaderParam{ID: id[0], Value: value})
+	}
+	return h, nil
+}
+
+// Param возвращает значение параметра по id.
+func (h *Header) Param(id byte) ([]byte, bool) {
+	for _, p := range h.Params {
+		if p.ID == id {
+			return p.Value, true
+		}
+	}
+	return nil, false
+}
+
+func readShortField(r io.Reader) ([]byte, error) {
+	var n [1]byte
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return nil, errors.New("truncated header")
+	}
+	field := make([]byte, n[0])
+	if _, err := io.ReadFull(r, field); err != nil {
+		return nil, errors.New("truncated header")
+	}
+	return field, nil
+}
+
+func nameByID(ids map[string]byte, id byte, what string) (string, error) {
+	for name, v := range ids {
+		if v == id {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown %s id %d in header", what, id)
+}
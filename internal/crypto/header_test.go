@@ -0,0 +1,68 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestHeader_RoundTrip(t *testing.T) {
+	h := &Header{
+		Cipher:     "aes-256",
+		Mode:       "gcm",
+		KDF:        KDFPBKDF2SHA256,
+		Iterations: 100000,
+		Salt:       bytes.Repeat([]byte{0x11}, 16),
+		IV:         bytes.Repeat([]byte{0x22}, GCMNonceSize),
+	}
+
+	raw, err := h.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw[:4]) != HeaderMagic || raw[4] != HeaderVersion {
+		t.Fatalf("unexpected header prefix %x", raw[:5])
+	}
+
+	body := []byte("ciphertext")
+	r := bytes.NewReader(append(append([]byte(nil), raw...), body...))
+	got, err := ReadHeader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, h) {
+		t.Fatalf("header mismatch:\ngot:  %+v\nwant: %+v", got, h)
+	}
+
+	rest := make([]byte, len(body))
+	if _, err := r.Read(rest); err != nil || !bytes.Equal(rest, body) {
+		t.Fatalf("reader not positioned at ciphertext: %q", rest)
+	}
+
+	again, _ := got.Marshal()
+	if !bytes.Equal(again, raw) {
+		t.Fatalf("re-marshalled header differs")
+	}
+}
+
+func TestHeader_Errors(t *testing.T) {
+	if _, err := ReadHeader(bytes.NewReader([]byte("legacy-data-without-header"))); !errors.Is(err, ErrNoHeader) {
+		t.Fatalf("expected ErrNoHeader, got %v", err)
+	}
+
+	raw, _ := (&Header{Cipher: "aes-128", Mode: "cbc", IV: make([]byte, 16)}).Marshal()
+	if _, err := ReadHeader(bytes.NewReader(raw[:len(raw)-3])); err == nil {
+		t.Fatalf("expected error for truncated header")
+	}
+
+	bad := append([]byte(nil), raw...)
+	bad[4] = HeaderVersion + 1
+	if _, err := ReadHeader(bytes.NewReader(bad)); err == nil {
+		t.Fatalf("expected error for unknown version")
+	}
+
+	if _, err := (&Header{Cipher: "des", Mode: "cbc"}).Marshal(); err == nil {
+		t.Fatalf("expected error for unknown cipher")
+	}
+}
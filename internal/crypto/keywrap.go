@@ -0,0 +1,164 @@
+package crypto
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"cryptcore/internal/blockcipher"
+)
+
+// AES Key Wrap (RFC 3394) и Key Wrap with Padding (RFC 5649) для хранения ключей данных
+// под мастер-ключом (KEK). Обёрнутый ключ на 8 байт длиннее (без учёта дополнения),
+// целостность проверяется по начальному значению A: фиксированному IV в RFC 3394 и
+// AIV = A65959A6 || длина в RFC 5649.
+const keyWrapSemiblock = 8
+
+var (
+	keyWrapDefaultIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+	keyWrapAIVPrefix = []byte{0xa6, 0x59, 0x59, 0xa6}
+)
+
+// ErrKeyUnwrap: неверный KEK или повреждённые данные — проверка IV/AIV не прошла.
+var ErrKeyUnwrap = errors.New("key unwrap failed: integrity check error (wrong KEK or corrupted data)")
+
+// WrapKey оборачивает key (кратно 8 байтам, не меньше 16) ключом kek (16/24/32 байта).
+func WrapKey(kek, key []byte) ([]byte, error) {
+	if len(key) < 2*keyWrapSemiblock || len(key)%keyWrapSemiblock != 0 {
+		return nil, errors.New("key to wrap must be a multiple of 8 bytes and at least 16 bytes; use padded wrap for other sizes")
+	}
+	return keyWrap(kek, keyWrapDefaultIV, key)
+}
+
+// UnwrapKey — обратная операция; при несовпадении IV возвращает ErrKeyUnwrap.
+func UnwrapKey(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 3*keyWrapSemiblock || len(wrapped)%keyWrapSemiblock != 0 {
+		return nil, errors.New("wrapped key must be a multiple of 8 bytes and at least 24 bytes")
+	}
+	a, key, err := keyUnwrap(kek, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(a, keyWrapDefaultIV) != 1 {
+		return nil, ErrKeyUnwrap
+	}
+	return key, nil
+}
+
+// WrapKeyWithPadding (RFC 5649) принимает ключ любой длины от 1 байта.
+func WrapKeyWithPadding(kek, key []byte) ([]byte, error) {
+	if len(key) == 0 || uint64(len(key)) > 0xffffffff {
+		return nil, errors.New("key to wrap must be between 1 byte and 4 GiB")
+	}
+	aiv := make([]byte, keyWrapSemiblock)
+	copy(aiv, keyWrapAIVPrefix)
+	binary.BigEndian.PutUint32(aiv[4:], uint32(len(key)))
+
+	padded := make([]byte, (len(key)+keyWrapSemiblock-1)/keyWrapSemiblock*keyWrapSemiblock)
+	copy(padded, key)
+
+	if len(padded) == keyWrapSemiblock {
+		// один полублок: AIV || P шифруется одним блоком AES (RFC 5649, 4.1)
+		block, err := blockcipher.NewAES(kek)
+		if err != nil {
+			return nil, err
+		}
+		out := append(aiv, padded...)
+		block.Encrypt(out, out)
+		return out, nil
+	}
+	return keyWrap(kek, aiv, padded)
+}
+
+// UnwrapKeyWithPadding проверяет AIV, длину и нулевое дополнение.
+func UnwrapKeyWithPadding(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 2*keyWrapSemiblock || len(wrapped)%keyWrapSemiblock != 0 {
+		return nil, errors.New("wrapped key must be a multiple of 8 bytes and at least 16 bytes")
+	}
+
+	var a, padded []byte
+	if len(wrapped) == 2*keyWrapSemiblock {
+		block, err := blockcipher.NewAES(kek)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, BlockSize)
+		block.Decrypt(out, wrapped)
+		a, padded = out[:keyWrapSemiblock], out[keyWrapSemiblock:]
+	} else {
+		var err error
+		if a, padded, err = keyUnwrap(kek, wrapped); err != nil {
+			return nil, err
+		}
+	}
+
+	// все проверки без раннего выхода, чтобы не различать причины отказа по времени
+	ok := subtle.ConstantTimeCompare(a[:4], keyWrapAIVPrefix)
+	// MLI от 2^31 отбрасывается сразу: ConstantTimeLessOrEq определена только до 2^31-1
+	ok &= subtle.ConstantTimeByteEq(a[4]&0x80, 0)
+	mli := int(binary.BigEndian.Uint32(a[4:]) & 0x7fffffff)
+	ok &= subtle.ConstantTimeLessOrEq(len(padded)-keyWrapSemiblock+1, mli)
+	ok &= subtle.ConstantTimeLessOrEq(mli, len(padded))
+	if ok != 1 {
+		return nil, ErrKeyUnwrap
+	}
+	var nonzero byte
+	for _, b := range padded[mli:] {
+		nonzero |= b
+	}
+	if nonzero != 0 {
+		return nil, ErrKeyUnwrap
+	}
+	return padded[:mli], nil
+}
+
+// keyWrap: W(S) из RFC 3394, 2.2.1 — 6n шагов над полублоками R[1..n] с регистром A.
+func keyWrap(kek, iv, plaintext []byte) ([]byte, error) {
+	block, err := blockcipher.NewAES(kek)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KEK: %w", err)
+	}
+	n := len(plaintext) / keyWrapSemiblock
+	out := make([]byte, len(plaintext)+keyWrapSemiblock)
+	copy(out, iv)
+	copy(out[keyWrapSemiblock:], plaintext)
+
+	var b [BlockSize]byte
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			r := out[i*keyWrapSemiblock : (i+1)*keyWrapSemiblock]
+			copy(b[:8], out[:8])
+			copy(b[8:], r)
+			block.Encrypt(b[:], b[:])
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(r, b[8:])
+		}
+	}
+	return out, nil
+}
+
+// keyUnwrap: W^-1 из RFC 3394, 2.2.2; возвращает A для проверки вызывающим.
+func keyUnwrap(kek, wrapped []byte) (a, plaintext []byte, err error) {
+	block, err := blockcipher.NewAES(kek)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid KEK: %w", err)
+	}
+	n := len(wrapped)/keyWrapSemiblock - 1
+	out := append([]byte(nil), wrapped...)
+
+	var b [BlockSize]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			r := out[i*keyWrapSemiblock : (i+1)*keyWrapSemiblock]
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(out[:8])^t)
+			copy(b[8:], r)
+			block.Decrypt(b[:], b[:])
+			copy(out[:8], b[:8])
+			copy(r, b[8:])
+		}
+	}
+	return out[:keyWrapSemiblock], out[keyWrapSemiblock:], nil
+}
@@ -0,0 +1,120 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"cryptcore/internal/blockcipher"
+)
+
+const (
+	kek128 = "000102030405060708090a0b0c0d0e0f"
+	kek192 = "000102030405060708090a0b0c0d0e0f1011121314151617"
+	kek256 = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+)
+
+func TestKeyWrap_RFC3394(t *testing.T) {
+	// RFC 3394, 4.1-4.6
+	cases := []struct {
+		name, kek, key, wrapped string
+	}{
+		{"4.1", kek128, "00112233445566778899aabbccddeeff", "1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5"},
+		{"4.2", kek192, "00112233445566778899aabbccddeeff", "96778b25ae6ca435f92b5b97c050aed2468ab8a17ad84e5d"},
+		{"4.3", kek256, "00112233445566778899aabbccddeeff", "64e8c3f9ce0f5ba263e9777905818a2a93c8191e7d6e8ae7"},
+		{"4.4", kek192, "00112233445566778899aabbccddeeff0001020304050607", "031d33264e15d33268f24ec260743edce1c6c7ddee725a936ba814915c6762d2"},
+		{"4.5", kek256, "00112233445566778899aabbccddeeff0001020304050607", "a8f9bc1612c68b3ff6e6f4fbe30e71e4769c8b80a32cb8958cd5d17d6b254da1"},
+		{"4.6", kek256, "00112233445566778899aabbccddeeff000102030405060708090a0b0c0d0e0f", "28c9f404c4b810f4cbccb35cfb87f8263f5786e2d80ed326cbc7f0e71a99f43bfb988b9b7a02dd21"},
+	}
+	for _, c := range cases {
+		kek := mustHex(t, c.kek)
+		wrapped, err := WrapKey(kek, mustHex(t, c.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(wrapped); got != c.wrapped {
+			t.Fatalf("%s: wrap mismatch:\ngot:  %s\nwant: %s", c.name, got, c.wrapped)
+		}
+		key, err := UnwrapKey(kek, wrapped)
+		if err != nil || hex.EncodeToString(key) != c.key {
+			t.Fatalf("%s: unwrap failed: %v", c.name, err)
+		}
+
+		wrapped[len(wrapped)-1] ^= 0x01
+		if _, err := UnwrapKey(kek, wrapped); !errors.Is(err, ErrKeyUnwrap) {
+			t.Fatalf("%s: expected ErrKeyUnwrap, got %v", c.name, err)
+		}
+	}
+}
+
+func TestKeyWrapWithPadding_RFC5649(t *testing.T) {
+	// RFC 5649, 6
+	kek := mustHex(t, "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8")
+	cases := []struct {
+		key, wrapped string
+	}{
+		{"c37b7e6492584340bed12207808941155068f738", "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a"},
+		{"466f7250617369", "afbeb0f07dfbf5419200f2ccb50bb24f"},
+	}
+	for _, c := range cases {
+		wrapped, err := WrapKeyWithPadding(kek, mustHex(t, c.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(wrapped); got != c.wrapped {
+			t.Fatalf("wrap mismatch:\ngot:  %s\nwant: %s", got, c.wrapped)
+		}
+		key, err := UnwrapKeyWithPadding(kek, wrapped)
+		if err != nil || hex.EncodeToString(key) != c.key {
+			t.Fatalf("unwrap failed: %v", err)
+		}
+	}
+}
+
+func TestKeyWrap_RejectsWrongVariantAndKEK(t *testing.T) {
+	kek := mustHex(t, kek128)
+	key := bytes.Repeat([]byte{0x42}, 24)
+
+	plain, _ := WrapKey(kek, key)
+	if _, err := UnwrapKeyWithPadding(kek, plain); !errors.Is(err, ErrKeyUnwrap) {
+		t.Fatalf("padded unwrap of RFC 3394 data: expected ErrKeyUnwrap, got %v", err)
+	}
+	padded, _ := WrapKeyWithPadding(kek, key)
+	if _, err := UnwrapKey(kek, padded); !errors.Is(err, ErrKeyUnwrap) {
+		t.Fatalf("RFC 3394 unwrap of padded data: expected ErrKeyUnwrap, got %v", err)
+	}
+	if _, err := UnwrapKey(bytes.Repeat([]byte{0x11}, 16), plain); !errors.Is(err, ErrKeyUnwrap) {
+		t.Fatalf("wrong KEK: expected ErrKeyUnwrap, got %v", err)
+	}
+	if _, err := WrapKey(kek, key[:20]); err == nil {
+		t.Fatalf("expected error for 20-byte key without padding")
+	}
+}
+
+func TestKeyWrapWithPadding_RejectsHugeMLI(t *testing.T) {
+	kek := mustHex(t, kek128)
+	block, err := blockcipher.NewAES(kek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mli := range []uint32{0x80000000, 0x80000008, 0x80000010, 0xfffffff8, 0xffffffff} {
+		aiv := binary.BigEndian.AppendUint32(append([]byte(nil), keyWrapAIVPrefix...), mli)
+
+		// один полублок: AIV||P шифруется одним блоком AES
+		single := make([]byte, BlockSize)
+		block.Encrypt(single, append(aiv, bytes.Repeat([]byte{0x42}, keyWrapSemiblock)...))
+		if _, err := UnwrapKeyWithPadding(kek, single); !errors.Is(err, ErrKeyUnwrap) {
+			t.Fatalf("MLI %#x, one semiblock: expected ErrKeyUnwrap, got %v", mli, err)
+		}
+
+		multi, err := keyWrap(kek, aiv, bytes.Repeat([]byte{0x42}, 3*keyWrapSemiblock))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := UnwrapKeyWithPadding(kek, multi); !errors.Is(err, ErrKeyUnwrap) {
+			t.Fatalf("MLI %#x, three semiblocks: expected ErrKeyUnwrap, got %v", mli, err)
+		}
+	}
+}
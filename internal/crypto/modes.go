@@ -1,15 +1,16 @@
 package crypto
 
 import (
-	"crypto/aes"
 	"errors"
 	"fmt"
+
+	"cryptcore/internal/blockcipher"
 )
 
 // EncryptWithIVMode: для CBC/CFB/OFB/CTR при шифровании.
 // Формат файла: <16-байтный IV>iphertext>.
 func EncryptWithIVMode(mode string, key, plaintext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
+	block, err := blockcipher.NewAES(key)
 	if err != nil {
 		return nil, err
 	}
@@ -29,6 +30,9 @@ func EncryptWithIVMode(mode string, key, plaintext []byte) ([]byte, error) {
 		ciphertext, err = encryptCBC(block, iv, plaintext)
 	case "cfb":
 		ciphertext, err = encryptCFB(block, iv, plaintext)
+	case "cfb8", "cfb1":
+		ciphertext = make([]byte, len(plaintext))
+		newCFBSegmentStream(block, iv, cfbSegmentBits[mode], false).xorKeyStream(ciphertext, plaintext)
 	case "ofb":
 		ciphertext, err = encryptOFB(block, iv, plaintext)
 	case "ctr":
@@ -69,7 +73,7 @@ func DecryptWithIVMode(mode string, key, input []byte, ivHex string, useIVFlag b
 		ciphertext = input[BlockSize:]
 	}
 
-	block, err := aes.NewCipher(key)
+	block, err := blockcipher.NewAES(key)
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +83,10 @@ func DecryptWithIVMode(mode string, key, input []byte, ivHex string, useIVFlag b
 		return decryptCBC(block, iv, ciphertext)
 	case "cfb":
 		return decryptCFB(block, iv, ciphertext)
+	case "cfb8", "cfb1":
+		out := make([]byte, len(ciphertext))
+		newCFBSegmentStream(block, iv, cfbSegmentBits[mode], true).xorKeyStream(out, ciphertext)
+		return out, nil
 	case "ofb":
 		return decryptOFB(block, iv, ciphertext)
 	case "ctr":
@@ -96,17 +104,7 @@ func encryptCBC(block cipherBlock, iv, plaintext []byte) ([]byte, error) {
 	}
 
 	out := make([]byte, len(padded))
-	prev := make([]byte, BlockSize)
-	copy(prev, iv)
-
-	tmp := make([]byte, BlockSize)
-
-	for bs := 0; bs < len(padded); bs += BlockSize {
-		be := bs + BlockSize
-		xorBlocks(tmp, padded[bs:be], prev)
-		block.Encrypt(out[bs:be], tmp)
-		copy(prev, out[bs:be])
-	}
+	newCBCEncrypter(block, iv).cryptBlocks(out, padded)
 	return out, nil
 }
 
@@ -115,87 +113,30 @@ func decryptCBC(block cipherBlock, iv, ciphertext []byte) ([]byte, error) {
 		return nil, errors.New("ciphertext length must be multiple of block size for CBC")
 	}
 	out := make([]byte, len(ciphertext))
-	prev := make([]byte, BlockSize)
-	copy(prev, iv)
-
-	tmp := make([]byte, BlockSize)
-
-	for bs := 0; bs < len(ciphertext); bs += BlockSize {
-		be := bs + BlockSize
-		block.Decrypt(tmp, ciphertext[bs:be])
-		xorBlocks(out[bs:be], tmp, prev)
-		copy(prev, ciphertext[bs:be])
-	}
+	newCBCDecrypter(block, iv).cryptBlocks(out, ciphertext)
 	return PKCS7Unpad(out, BlockSize)
 }
 
+// cfbSegmentBits: размер сегмента (бит) для режимов cfb8/cfb1.
+var cfbSegmentBits = map[string]int{"cfb8": 8, "cfb1": 1}
+
 // CFB (stream, no padding)
 func encryptCFB(block cipherBlock, iv, plaintext []byte) ([]byte, error) {
 	out := make([]byte, len(plaintext))
-	prev := make([]byte, BlockSize)
-	copy(prev, iv)
-
-	keystream := make([]byte, BlockSize)
-
-	for i := 0; i < len(plaintext); {
-		block.Encrypt(keystream, prev)
-		n := BlockSize
-		if len(plaintext)-i < BlockSize {
-			n = len(plaintext) - i
-		}
-		for j := 0; j < n; j++ {
-			out[i+j] = plaintext[i+j] ^ keystream[j]
-		}
-		copy(prev, prev[0:])
-		copy(prev, out[i:i+n]) // shift = full block
-		i += n
-	}
+	newCFBStream(block, iv, false).xorKeyStream(out, plaintext)
 	return out, nil
 }
 
 func decryptCFB(block cipherBlock, iv, ciphertext []byte) ([]byte, error) {
 	out := make([]byte, len(ciphertext))
-	prev := make([]byte, BlockSize)
-	copy(prev, iv)
-
-	keystream := make([]byte, BlockSize)
-
-	for i := 0; i < len(ciphertext); {
-		block.Encrypt(keystream, prev)
-		n := BlockSize
-		if len(ciphertext)-i < BlockSize {
-			n = len(ciphertext) - i
-		}
-		for j := 0; j < n; j++ {
-			out[i+j] = ciphertext[i+j] ^ keystream[j]
-		}
-		copy(prev, prev[0:])
-		copy(prev, ciphertext[i:i+n])
-		i += n
-	}
+	newCFBStream(block, iv, true).xorKeyStream(out, ciphertext)
 	return out, nil
 }
 
 // OFB (stream, keystream independent of plaintext)
 func encryptOFB(block cipherBlock, iv, plaintext []byte) ([]byte, error) {
 	out := make([]byte, len(plaintext))
-	stream := make([]byte, BlockSize)
-	copy(stream, iv)
-
-	keystream := make([]byte, BlockSize)
-
-	for i := 0; i < len(plaintext); {
-		block.Encrypt(keystream, stream)
-		copy(stream, keystream)
-		n := BlockSize
-		if len(plaintext)-i < BlockSize {
-			n = len(plaintext) - i
-		}
-		for j := 0; j < n; j++ {
-			out[i+j] = plaintext[i+j] ^ keystream[j]
-		}
-		i += n
-	}
+	newOFBStream(block, iv).xorKeyStream(out, plaintext)
 	return out, nil
 }
 
@@ -207,23 +148,7 @@ func decryptOFB(block cipherBlock, iv, ciphertext []byte) ([]byte, error) {
 // CTR (stream, counter = IV + блоковый счётчик)
 func encryptCTR(block cipherBlock, iv, plaintext []byte) ([]byte, error) {
 	out := make([]byte, len(plaintext))
-	counter := make([]byte, BlockSize)
-	copy(counter, iv)
-
-	keystream := make([]byte, BlockSize)
-
-	for i := 0; i < len(plaintext); {
-		block.Encrypt(keystream, counter)
-		incrementCounter(counter)
-		n := BlockSize
-		if len(plaintext)-i < BlockSize {
-			n = len(plaintext) - i
-		}
-		for j := 0; j < n; j++ {
-			out[i+j] = plaintext[i+j] ^ keystream[j]
-		}
-		i += n
-	}
+	newCTRStream(block, iv, incrementCounter).xorKeyStream(out, plaintext)
 	return out, nil
 }
 
@@ -237,6 +162,234 @@ type cipherBlock interface {
 	Decrypt(dst, src []byte)
 }
 
+// blockMode: блочный режим с состоянием между вызовами; len(src) кратна BlockSize.
+type blockMode interface {
+	cryptBlocks(dst, src []byte)
+}
+
+// keyStream: потоковый режим с состоянием между вызовами; src любой длины.
+type keyStream interface {
+	xorKeyStream(dst, src []byte)
+}
+
+type ecbEncrypter struct{ block cipherBlock }
+
+func (x ecbEncrypter) cryptBlocks(dst, src []byte) {
+	for bs := 0; bs < len(src); bs += BlockSize {
+		x.block.Encrypt(dst[bs:bs+BlockSize], src[bs:bs+BlockSize])
+	}
+}
+
+type ecbDecrypter struct{ block cipherBlock }
+
+func (x ecbDecrypter) cryptBlocks(dst, src []byte) {
+	for bs := 0; bs < len(src); bs += BlockSize {
+		x.block.Decrypt(dst[bs:bs+BlockSize], src[bs:bs+BlockSize])
+	}
+}
+
+type cbcEncrypter struct {
+	block cipherBlock
+	prev  []byte
+	tmp   []byte
+}
+
+func newCBCEncrypter(block cipherBlock, iv []byte) *cbcEncrypter {
+	x := &cbcEncrypter{block: block, prev: make([]byte, BlockSize), tmp: make([]byte, BlockSize)}
+	copy(x.prev, iv)
+	return x
+}
+
+func (x *cbcEncrypter) cryptBlocks(dst, src []byte) {
+	for bs := 0; bs < len(src); bs += BlockSize {
+		be := bs + BlockSize
+		xorBlocks(x.tmp, src[bs:be], x.prev)
+		x.block.Encrypt(dst[bs:be], x.tmp)
+		copy(x.prev, dst[bs:be])
+	}
+}
+
+type cbcDecrypter struct {
+	block cipherBlock
+	prev  []byte
+	tmp   []byte
+}
+
+func newCBCDecrypter(block cipherBlock, iv []byte) *cbcDecrypter {
+	x := &cbcDecrypter{block: block, prev: make([]byte, BlockSize), tmp: make([]byte, BlockSize)}
+	copy(x.prev, iv)
+	return x
+}
+
+func (x *cbcDecrypter) cryptBlocks(dst, src []byte) {
+	next := make([]byte, BlockSize)
+	for bs := 0; bs < len(src); bs += BlockSize {
+		be := bs + BlockSize
+		copy(next, src[bs:be]) // dst и src могут совпадать
+		x.block.Decrypt(x.tmp, src[bs:be])
+		xorBlocks(dst[bs:be], x.tmp, x.prev)
+		copy(x.prev, next)
+	}
+}
+
+// cfbStream: CFB-128. Регистр сдвига заполняется байтами шифртекста по мере обработки,
+// поэтому неполные блоки можно подавать в разных вызовах.
+type cfbStream struct {
+	block     cipherBlock
+	register  []byte
+	keystream []byte
+	pos       int
+	decrypt   bool
+}
+
+func newCFBStream(block cipherBlock, iv []byte, decrypt bool) *cfbStream {
+	x := &cfbStream{
+		block:     block,
+		register:  make([]byte, BlockSize),
+		keystream: make([]byte, BlockSize),
+		pos:       BlockSize,
+		decrypt:   decrypt,
+	}
+	copy(x.register, iv)
+	return x
+}
+
+func (x *cfbStream) xorKeyStream(dst, src []byte) {
+	for i, c := range src {
+		if x.pos == BlockSize {
+			x.block.Encrypt(x.keystream, x.register)
+			x.pos = 0
+		}
+		out := c ^ x.keystream[x.pos]
+		if x.decrypt {
+			x.register[x.pos] = c
+		} else {
+			x.register[x.pos] = out
+		}
+		dst[i] = out
+		x.pos++
+	}
+}
+
+// cfbSegmentStream: CFB-8 и CFB-1 (SP 800-38A, 6.3 с s = 8 и s = 1). На каждый сегмент —
+// одно шифрование блока; регистр сдвигается на s бит влево и дополняется сегментом шифртекста.
+type cfbSegmentStream struct {
+	block     cipherBlock
+	register  []byte
+	keystream []byte
+	bits      int // 8 или 1
+	decrypt   bool
+}
+
+func newCFBSegmentStream(block cipherBlock, iv []byte, bits int, decrypt bool) *cfbSegmentStream {
+	x := &cfbSegmentStream{
+		block:     block,
+		register:  make([]byte, BlockSize),
+		keystream: make([]byte, BlockSize),
+		bits:      bits,
+		decrypt:   decrypt,
+	}
+	copy(x.register, iv)
+	return x
+}
+
+func (x *cfbSegmentStream) xorKeyStream(dst, src []byte) {
+	for i, c := range src {
+		if x.bits == 8 {
+			x.block.Encrypt(x.keystream, x.register)
+			out := c ^ x.keystream[0]
+			cipherByte := out
+			if x.decrypt {
+				cipherByte = c
+			}
+			copy(x.register, x.register[1:])
+			x.register[BlockSize-1] = cipherByte
+			dst[i] = out
+			continue
+		}
+
+		// CFB-1: биты байта обрабатываются от старшего к младшему
+		var out byte
+		for bit := 7; bit >= 0; bit-- {
+			x.block.Encrypt(x.keystream, x.register)
+			in := c >> uint(bit) & 1
+			o := in ^ x.keystream[0]>>7
+			cipherBit := o
+			if x.decrypt {
+				cipherBit = in
+			}
+			shiftLeft1(x.register, cipherBit)
+			out |= o << uint(bit)
+		}
+		dst[i] = out
+	}
+}
+
+// shiftLeft1 сдвигает b на один бит влево, младший бит последнего байта = in.
+func shiftLeft1(b []byte, in byte) {
+	for i := 0; i < len(b)-1; i++ {
+		b[i] = b[i]<<1 | b[i+1]>>7
+	}
+	b[len(b)-1] = b[len(b)-1]<<1 | in
+}
+
+type ofbStream struct {
+	block     cipherBlock
+	keystream []byte
+	pos       int
+}
+
+func newOFBStream(block cipherBlock, iv []byte) *ofbStream {
+	x := &ofbStream{block: block, keystream: make([]byte, BlockSize), pos: BlockSize}
+	copy(x.keystream, iv)
+	return x
+}
+
+func (x *ofbStream) xorKeyStream(dst, src []byte) {
+	for i, c := range src {
+		if x.pos == BlockSize {
+			x.block.Encrypt(x.keystream, x.keystream)
+			x.pos = 0
+		}
+		dst[i] = c ^ x.keystream[x.pos]
+		x.pos++
+	}
+}
+
+// ctrStream: inc задаёт правило инкремента счётчика
+// (весь блок для обычного CTR, младшие 32 бита для GCM).
+type ctrStream struct {
+	block     cipherBlock
+	counter   []byte
+	keystream []byte
+	pos       int
+	inc       func([]byte)
+}
+
+func newCTRStream(block cipherBlock, iv []byte, inc func([]byte)) *ctrStream {
+	x := &ctrStream{
+		block:     block,
+		counter:   make([]byte, BlockSize),
+		keystream: make([]byte, BlockSize),
+		pos:       BlockSize,
+		inc:       inc,
+	}
+	copy(x.counter, iv)
+	return x
+}
+
+func (x *ctrStream) xorKeyStream(dst, src []byte) {
+	for i, c := range src {
+		if x.pos == BlockSize {
+			x.block.Encrypt(x.keystream, x.counter)
+			x.inc(x.counter)
+			x.pos = 0
+		}
+		dst[i] = c ^ x.keystream[x.pos]
+		x.pos++
+	}
+}
+
 func incrementCounter(c []byte) {
 	for i := len(c) - 1; i >= 0; i-- {
 		c[i]++
@@ -0,0 +1,262 @@
+package crypto
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+// Способы выравнивания последнего блока в ECB/CBC (--padding). PKCS#7 — по умолчанию,
+// в заголовке контейнера он не записывается.
+const (
+	PaddingPKCS7    = "pkcs7"
+	PaddingCTSCS1   = "cts-cs1"
+	PaddingCTSCS2   = "cts-cs2"
+	PaddingCTSCS3   = "cts-cs3"
+	PaddingANSIX923 = "ansix923"
+	PaddingISO7816  = "iso7816"
+	PaddingISO10126 = "iso10126"
+	PaddingZero     = "zero"
+	PaddingNone     = "none"
+)
+
+// paddingIDs: значение параметра HeaderParamPadding; id менять нельзя, только добавлять.
+var paddingIDs = map[string]byte{
+	PaddingCTSCS1:   1,
+	PaddingCTSCS2:   2,
+	PaddingCTSCS3:   3,
+	PaddingANSIX923: 4,
+	PaddingISO7816:  5,
+	PaddingISO10126: 6,
+	PaddingZero:     7,
+	PaddingNone:     8,
+}
+
+// ErrInvalidPadding: единая ошибка снятия дополнения для всех схем — по тексту ошибки
+// нельзя узнать ни схему, ни то, какая именно проверка не прошла. Проверяемые схемы
+// (PKCS#7, ANSI X9.23, ISO 7816-4) просматривают весь последний блок с масками
+// subtle, без раннего выхода, чтобы и время работы не выдавало ту же информацию.
+var ErrInvalidPadding = errors.New("invalid padding")
+
+// IsPadding сообщает, допустимо ли имя в --padding.
+func IsPadding(name string) bool {
+	_, ok := paddingIDs[name]
+	return ok || name == PaddingPKCS7
+}
+
+// PaddingID / PaddingName переводят имя в значение параметра заголовка и обратно.
+func PaddingID(name string) (byte, error) {
+	id, ok := paddingIDs[name]
+	if !ok {
+		return 0, fmt.Errorf("padding %q has no header id", name)
+	}
+	return id, nil
+}
+
+func PaddingName(id byte) (string, error) {
+	return nameByID(paddingIDs, id, "padding")
+}
+
+// Padding: схема дополнения последнего блока. Pad возвращает данные, кратные blockSize;
+// Unpad снимает дополнение и при любой ошибке возвращает ErrInvalidPadding.
+type Padding interface {
+	Pad(data []byte, blockSize int) ([]byte, error)
+	Unpad(data []byte, blockSize int) ([]byte, error)
+}
+
+// NewPadding возвращает схему по имени из --padding; пустое имя — PKCS#7.
+// Кража шифртекста не дополняет данные и здесь не поддерживается (см. NewCTSWriter).
+func NewPadding(name string) (Padding, error) {
+	switch name {
+	case "", PaddingPKCS7:
+		return pkcs7Padding{}, nil
+	case PaddingANSIX923:
+		return x923Padding{}, nil
+	case PaddingISO7816:
+		return iso7816Padding{}, nil
+	case PaddingISO10126:
+		return iso10126Padding{}, nil
+	case PaddingZero:
+		return zeroPadding{}, nil
+	case PaddingNone:
+		return noPadding{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported padding %q", name)
+	}
+}
+
+type pkcs7Padding struct{}
+
+func (pkcs7Padding) Pad(data []byte, blockSize int) ([]byte, error) {
+	return PKCS7Pad(data, blockSize)
+}
+
+func (pkcs7Padding) Unpad(data []byte, blockSize int) ([]byte, error) {
+	return PKCS7Unpad(data, blockSize)
+}
+
+// padded: копия data с местом под 1..blockSize байт дополнения (заполнено нулями).
+func padded(data []byte, blockSize int) ([]byte, int, error) {
+	if blockSize <= 0 || blockSize >= 256 {
+		return nil, 0, errors.New("invalid block size for padding")
+	}
+	padLen := blockSize - len(data)%blockSize
+	out := make([]byte, len(data)+padLen)
+	copy(out, data)
+	return out, padLen, nil
+}
+
+// lastBlock: последний блок data; длина данных не секретна, её можно проверять ветвлением.
+func lastBlock(data []byte, blockSize int) ([]byte, error) {
+	if blockSize <= 0 || len(data) == 0 || len(data)%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+	return data[len(data)-blockSize:], nil
+}
+
+// lengthByte: длина дополнения из последнего байта (PKCS#7, ANSI X9.23, ISO 10126)
+// и маска 1/0 того, что она в пределах 1..len(last).
+func lengthByte(last []byte) (padLen, good int) {
+	padLen = int(last[len(last)-1])
+	good = subtle.ConstantTimeLessOrEq(1, padLen) & subtle.ConstantTimeLessOrEq(padLen, len(last))
+	return padLen, good
+}
+
+// inPadding: 1, если байт i последнего блока попадает в дополнение длины padLen.
+func inPadding(i, padLen, blockSize int) int {
+	return subtle.ConstantTimeLessOrEq(blockSize, i+padLen)
+}
+
+// x923Padding: ANSI X9.23 — нули и последний байт с длиной дополнения.
+type x923Padding struct{}
+
+func (x923Padding) Pad(data []byte, blockSize int) ([]byte, error) {
+	out, padLen, err := padded(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	out[len(out)-1] = byte(padLen)
+	return out, nil
+}
+
+func (x923Padding) Unpad(data []byte, blockSize int) ([]byte, error) {
+	last, err := lastBlock(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	padLen, good := lengthByte(last)
+	for i, b := range last[:blockSize-1] {
+		zero := subtle.ConstantTimeByteEq(b, 0)
+		good &= subtle.ConstantTimeSelect(inPadding(i, padLen, blockSize), zero, 1)
+	}
+	if good != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return data[:len(data)-padLen], nil
+}
+
+// iso7816Padding: ISO/IEC 7816-4 — байт 0x80 и нули до конца блока.
+type iso7816Padding struct{}
+
+func (iso7816Padding) Pad(data []byte, blockSize int) ([]byte, error) {
+	out, _, err := padded(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	out[len(data)] = 0x80
+	return out, nil
+}
+
+func (iso7816Padding) Unpad(data []byte, blockSize int) ([]byte, error) {
+	last, err := lastBlock(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	// маркер ищется только в последнем блоке; блок просматривается целиком с конца,
+	// после маркера допустимы только нули
+	found, bad, pos := 0, 0, 0
+	for i := blockSize - 1; i >= 0; i-- {
+		zero := subtle.ConstantTimeByteEq(last[i], 0)
+		marker := subtle.ConstantTimeByteEq(last[i], 0x80)
+		before := found ^ 1
+		bad |= before &^ (zero | marker)
+		pos = subtle.ConstantTimeSelect(before&marker, i, pos)
+		found |= before & marker
+	}
+	if found&^bad != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return data[:len(data)-blockSize+pos], nil
+}
+
+// iso10126Padding: ISO 10126 — случайные байты и последний байт с длиной дополнения.
+type iso10126Padding struct{}
+
+func (iso10126Padding) Pad(data []byte, blockSize int) ([]byte, error) {
+	out, padLen, err := padded(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	if padLen > 1 {
+		random, err := GenerateRandomBytes(padLen - 1)
+		if err != nil {
+			return nil, err
+		}
+		copy(out[len(data):], random)
+	}
+	out[len(out)-1] = byte(padLen)
+	return out, nil
+}
+
+func (iso10126Padding) Unpad(data []byte, blockSize int) ([]byte, error) {
+	last, err := lastBlock(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	padLen, good := lengthByte(last)
+	if good != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return data[:len(data)-padLen], nil
+}
+
+// zeroPadding: нули до границы блока; выровненные данные не дополняются. Неоднозначно:
+// нулевые байты в конце открытого текста при снятии дополнения теряются.
+type zeroPadding struct{}
+
+func (zeroPadding) Pad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize <= 0 {
+		return nil, errors.New("invalid block size for padding")
+	}
+	out := make([]byte, (len(data)+blockSize-1)/blockSize*blockSize)
+	copy(out, data)
+	return out, nil
+}
+
+func (zeroPadding) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize <= 0 || len(data)%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+	n := len(data)
+	for n > 0 && n > len(data)-blockSize && data[n-1] == 0 {
+		n--
+	}
+	return data[:n], nil
+}
+
+// noPadding: без дополнения, длина открытого текста должна быть кратна блоку.
+type noPadding struct{}
+
+func (noPadding) Pad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize <= 0 || len(data)%blockSize != 0 {
+		return nil, fmt.Errorf("padding none requires input to be a multiple of %d bytes", blockSize)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (noPadding) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize <= 0 || len(data)%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+	return data, nil
+}
@@ -0,0 +1,207 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestPadding_Schemes(t *testing.T) {
+	data := []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd}
+	cases := []struct {
+		name, padded string
+	}{
+		{PaddingPKCS7, "dddddddddd030303"},
+		{PaddingANSIX923, "dddddddddd000003"},
+		{PaddingISO7816, "dddddddddd800000"},
+		{PaddingZero, "dddddddddd000000"},
+	}
+	for _, c := range cases {
+		p, err := NewPadding(c.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := p.Pad(data, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(out); got != c.padded {
+			t.Fatalf("%s: got %s, want %s", c.name, got, c.padded)
+		}
+		back, err := p.Unpad(out, 8)
+		if err != nil || !bytes.Equal(back, data) {
+			t.Fatalf("%s: unpad failed: %x, %v", c.name, back, err)
+		}
+	}
+
+	// ISO 10126: хвост случайный, проверяется только байт длины
+	p, _ := NewPadding(PaddingISO10126)
+	out, _ := p.Pad(data, 8)
+	if len(out) != 8 || out[7] != 3 || !bytes.Equal(out[:5], data) {
+		t.Fatalf("iso10126: bad padding %x", out)
+	}
+
+	// выровненные данные: все схемы, кроме zero и none, добавляют целый блок
+	block := bytes.Repeat([]byte{0x01}, 8)
+	for _, name := range []string{PaddingPKCS7, PaddingANSIX923, PaddingISO7816, PaddingISO10126, PaddingZero, PaddingNone} {
+		p, _ := NewPadding(name)
+		out, err := p.Pad(block, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 16
+		if name == PaddingZero || name == PaddingNone {
+			want = 8
+		}
+		if len(out) != want {
+			t.Fatalf("%s: padded length %d, want %d", name, len(out), want)
+		}
+	}
+
+	none, _ := NewPadding(PaddingNone)
+	if _, err := none.Pad(data, 8); err == nil {
+		t.Fatal("none: expected error for unaligned input")
+	}
+}
+
+func TestPadding_UniformUnpadErrors(t *testing.T) {
+	bad := map[string][]string{
+		PaddingPKCS7:    {"", "dddddddddddddd", "dddddddddddddd00", "dddddddddddd0203", "dddddddddddddd09"},
+		PaddingANSIX923: {"", "dddddddddddddd00", "dddddddddddd0103", "dddddddddddddd09"},
+		PaddingISO7816:  {"", "dddddddddddddd00", "dddddddddddddddd", "dddddd8000000001", "0000000000000000"},
+		PaddingISO10126: {"", "dddddddddddddd00", "dddddddddddddd09"},
+		PaddingNone:     {"dddddddddddddd"},
+	}
+	for name, inputs := range bad {
+		p, _ := NewPadding(name)
+		for _, in := range inputs {
+			data, _ := hex.DecodeString(in)
+			if _, err := p.Unpad(data, 8); err != ErrInvalidPadding {
+				t.Fatalf("%s/%s: expected ErrInvalidPadding, got %v", name, in, err)
+			}
+		}
+	}
+	// неверный размер блока — ошибка, а не деление на ноль
+	for _, name := range []string{PaddingPKCS7, PaddingANSIX923, PaddingISO7816, PaddingISO10126, PaddingZero, PaddingNone} {
+		p, _ := NewPadding(name)
+		for _, blockSize := range []int{0, -8} {
+			if _, err := p.Unpad(make([]byte, 8), blockSize); err != ErrInvalidPadding {
+				t.Fatalf("%s/block size %d: expected ErrInvalidPadding, got %v", name, blockSize, err)
+			}
+		}
+	}
+}
+
+func TestPadding_StreamRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{0x5a}, 16)
+	iv := bytes.Repeat([]byte{0xa5}, BlockSize)
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = byte(i + 1) // без нулей в конце: zero-дополнение их бы съело
+	}
+
+	for _, mode := range []string{"ecb", "cbc"} {
+		for _, name := range []string{PaddingPKCS7, PaddingANSIX923, PaddingISO7816, PaddingISO10126, PaddingZero, PaddingNone} {
+			p, _ := NewPadding(name)
+			for _, n := range []int{0, 1, 15, 16, 17, 64, 100} {
+				if name == PaddingNone && n%BlockSize != 0 {
+					continue
+				}
+				var enc bytes.Buffer
+				w, err := NewPaddedEncryptWriter("aes-128", mode, p, key, iv, &enc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				writeInPieces(t, w, data[:n])
+				if err := w.Close(); err != nil {
+					t.Fatalf("%s/%s/%d: %v", mode, name, n, err)
+				}
+
+				var dec bytes.Buffer
+				r, _ := NewPaddedDecryptWriter("aes-128", mode, p, key, iv, &dec)
+				writeInPieces(t, r, enc.Bytes())
+				if err := r.Close(); err != nil {
+					t.Fatalf("%s/%s/%d: decrypt: %v", mode, name, n, err)
+				}
+				if !bytes.Equal(dec.Bytes(), data[:n]) {
+					t.Fatalf("%s/%s/%d: round trip mismatch", mode, name, n)
+				}
+			}
+		}
+	}
+
+	// неверный ключ: ошибка та же, что и у любого другого повреждения дополнения
+	var enc bytes.Buffer
+	w, _ := NewPaddedEncryptWriter("aes-128", "cbc", iso7816Padding{}, key, iv, &enc)
+	w.Write(data[:20])
+	w.Close()
+	r, _ := NewPaddedDecryptWriter("aes-128", "cbc", iso7816Padding{}, bytes.Repeat([]byte{0x11}, 16), iv, &bytes.Buffer{})
+	r.Write(enc.Bytes())
+	if err := r.Close(); !errors.Is(err, ErrInvalidPadding) {
+		t.Fatalf("wrong key: expected ErrInvalidPadding, got %v", err)
+	}
+}
+
+// Снятие дополнения без ветвлений сверяется с простой эталонной реализацией на всех
+// последних блоках длины 4 из «интересных» байтов.
+func TestPadding_UnpadMatchesReference(t *testing.T) {
+	const blockSize = 4
+	reference := map[string]func(last []byte) int{ // длина дополнения или -1
+		PaddingPKCS7: func(last []byte) int {
+			n := int(last[blockSize-1])
+			if n == 0 || n > blockSize {
+				return -1
+			}
+			for _, b := range last[blockSize-n:] {
+				if int(b) != n {
+					return -1
+				}
+			}
+			return n
+		},
+		PaddingANSIX923: func(last []byte) int {
+			n := int(last[blockSize-1])
+			if n == 0 || n > blockSize {
+				return -1
+			}
+			for _, b := range last[blockSize-n : blockSize-1] {
+				if b != 0 {
+					return -1
+				}
+			}
+			return n
+		},
+		PaddingISO7816: func(last []byte) int {
+			i := blockSize - 1
+			for i > 0 && last[i] == 0 {
+				i--
+			}
+			if last[i] != 0x80 {
+				return -1
+			}
+			return blockSize - i
+		},
+	}
+	alphabet := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x80, 0xdd}
+	prefix := []byte{0xaa, 0xbb, 0xcc, 0xdd}
+
+	for name, ref := range reference {
+		p, _ := NewPadding(name)
+		last := make([]byte, blockSize)
+		for n := 0; n < 1<<(3*blockSize); n++ {
+			for i := range last {
+				last[i] = alphabet[n>>(3*i)&7]
+			}
+			data := append(append([]byte(nil), prefix...), last...)
+			got, err := p.Unpad(data, blockSize)
+			want := ref(last)
+			switch {
+			case want < 0 && err != ErrInvalidPadding:
+				t.Fatalf("%s/%x: expected ErrInvalidPadding, got %x, %v", name, last, got, err)
+			case want >= 0 && (err != nil || len(got) != len(data)-want):
+				t.Fatalf("%s/%x: got %x, %v; want %d padding bytes", name, last, got, err, want)
+			}
+		}
+	}
+}
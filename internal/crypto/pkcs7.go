@@ -1,6 +1,9 @@
 package crypto
 
-import "errors"
+import (
+	"crypto/subtle"
+	"errors"
+)
 
 func PKCS7Pad(data []byte, blockSize int) ([]byte, error) {
 	if blockSize <= 0 || blockSize >= 256 {
@@ -18,18 +21,20 @@ func PKCS7Pad(data []byte, blockSize int) ([]byte, error) {
 	return out, nil
 }
 
+// PKCS7Unpad проверяет весь последний блок без ветвлений по его содержимому:
+// время работы не зависит от того, какой байт дополнения оказался неверным.
 func PKCS7Unpad(data []byte, blockSize int) ([]byte, error) {
-	if len(data) == 0 || len(data)%blockSize != 0 {
-		return nil, errors.New("invalid padded data length")
+	last, err := lastBlock(data, blockSize)
+	if err != nil {
+		return nil, err
 	}
-	padLen := int(data[len(data)-1])
-	if padLen == 0 || padLen > blockSize || padLen > len(data) {
-		return nil, errors.New("invalid PKCS7 padding")
+	padLen, good := lengthByte(last)
+	for i, b := range last {
+		eq := subtle.ConstantTimeByteEq(b, byte(padLen))
+		good &= subtle.ConstantTimeSelect(inPadding(i, padLen, blockSize), eq, 1)
 	}
-	for i := len(data) - padLen; i < len(data); i++ {
-		if int(data[i]) != padLen {
-			return nil, errors.New("invalid PKCS7 padding bytes")
-		}
+	if good != 1 {
+		return nil, ErrInvalidPadding
 	}
 	return data[:len(data)-padLen], nil
 }
@@ -0,0 +1,148 @@
+package crypto
+
+import (
+	"crypto/subtle"
+	"errors"
+	"hash"
+
+	"cryptcore/internal/blockcipher"
+	"cryptcore/internal/mac"
+)
+
+// AES-SIV (RFC 5297): детерминированное AEAD, устойчивое к повтору nonce.
+// Синтетический IV V = S2V(K1, AD..., P) служит и тегом, и начальным счётчиком
+// CTR под ключом K2. Одинаковые (ключ, AD, P) дают одинаковый шифртекст, а повтор
+// открытого текста виден только целиком — без утечки структуры блоков, как в ECB.
+//
+// Формат: V (16 байт) || C.
+const SIVTagSize = BlockSize
+
+type siv struct {
+	cmac  hash.Hash
+	block cipherBlock
+}
+
+// NewSIV принимает ключ двойной длины K1||K2: 32, 48 или 64 байта (AES-SIV-128/192/256).
+func NewSIV(key []byte) (AEAD, error) {
+	switch len(key) {
+	case 32, 48, 64:
+	default:
+		return nil, errors.New("AES-SIV key must be 32, 48 or 64 bytes")
+	}
+	half := len(key) / 2
+	m, err := mac.NewCMAC(key[:half])
+	if err != nil {
+		return nil, err
+	}
+	block, err := blockcipher.NewAES(key[half:])
+	if err != nil {
+		return nil, err
+	}
+	return &siv{cmac: m, block: block}, nil
+}
+
+// NonceSize: nonce необязателен; если он задан, то входит в S2V как ещё один
+// компонент AD (RFC 5297, 3).
+func (s *siv) NonceSize() int { return 0 }
+
+func (s *siv) Overhead() int { return SIVTagSize }
+
+// Seal/Open: aad == nil — AD нет, непустой или пустой не-nil aad — один компонент
+// (RFC 5297 различает пустую строку AD и её отсутствие).
+func (s *siv) Seal(dst, nonce, plaintext, aad []byte) []byte {
+	return s.SealVector(dst, nonce, plaintext, sivAAD(aad))
+}
+
+func (s *siv) Open(dst, nonce, sealed, aad []byte) ([]byte, error) {
+	return s.OpenVector(dst, nonce, sealed, sivAAD(aad))
+}
+
+func (s *siv) SealVector(dst, nonce, plaintext []byte, ad [][]byte) []byte {
+	return s.seal(dst, plaintext, sivComponents(nonce, ad))
+}
+
+func (s *siv) OpenVector(dst, nonce, sealed []byte, ad [][]byte) ([]byte, error) {
+	return s.open(dst, sealed, sivComponents(nonce, ad))
+}
+
+func sivAAD(aad []byte) [][]byte {
+	if aad == nil {
+		return nil
+	}
+	return [][]byte{aad}
+}
+
+// sivComponents: nonce, если задан, — последний компонент AD.
+func sivComponents(nonce []byte, ad [][]byte) [][]byte {
+	if len(nonce) > 0 {
+		ad = append(ad[:len(ad):len(ad)], nonce)
+	}
+	return ad
+}
+
+func (s *siv) seal(dst, plaintext []byte, ad [][]byte) []byte {
+	v := s.s2v(ad, plaintext)
+	out := append(dst, v...)
+	out = append(out, make([]byte, len(plaintext))...)
+	s.ctr(v).xorKeyStream(out[len(dst)+SIVTagSize:], plaintext)
+	return out
+}
+
+func (s *siv) open(dst, sealed []byte, ad [][]byte) ([]byte, error) {
+	if len(sealed) < SIVTagSize {
+		return nil, ErrAuthFailed
+	}
+	v, ciphertext := sealed[:SIVTagSize], sealed[SIVTagSize:]
+	plaintext := make([]byte, len(ciphertext))
+	s.ctr(v).xorKeyStream(plaintext, ciphertext)
+
+	// V зависит от открытого текста, поэтому проверка возможна только после расшифрования;
+	// наружу P выдаётся лишь при совпадении
+	if subtle.ConstantTimeCompare(s.s2v(ad, plaintext), v) != 1 {
+		return nil, ErrAuthFailed
+	}
+	return append(dst, plaintext...), nil
+}
+
+// ctr: счётчик Q = V с обнулёнными битами 31 и 63 (RFC 5297, 2.6),
+// дальше обычный 128-битный инкремент.
+func (s *siv) ctr(v []byte) *ctrStream {
+	q := append([]byte(nil), v...)
+	q[8] &= 0x7f
+	q[12] &= 0x7f
+	return newCTRStream(s.block, q, incrementCounter)
+}
+
+// s2v (RFC 5297, 2.4) для компонентов ad и последнего компонента p.
+func (s *siv) s2v(ad [][]byte, p []byte) []byte {
+	d := s.cmacOf(make([]byte, BlockSize))
+	for _, a := range ad {
+		mac.Dbl(d)
+		xorBlocks(d, d, s.cmacOf(a))
+	}
+
+	s.cmac.Reset()
+	if len(p) >= BlockSize {
+		// T = P xorend D
+		n := len(p) - BlockSize
+		s.cmac.Write(p[:n])
+		tail := make([]byte, BlockSize)
+		xorBlocks(tail, p[n:], d)
+		s.cmac.Write(tail)
+	} else {
+		// T = dbl(D) xor pad(P)
+		mac.Dbl(d)
+		t := make([]byte, BlockSize)
+		copy(t, p)
+		t[len(p)] = 0x80
+		xorBlocks(t, t, d)
+		s.cmac.Write(t)
+	}
+	return s.cmac.Sum(nil)
+}
+
+func (s *siv) cmacOf(b []byte) []byte {
+	s.cmac.Reset()
+	s.cmac.Write(b)
+	return s.cmac.Sum(nil)
+}
@@ -0,0 +1,114 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestSIV_RFC5297Vectors(t *testing.T) {
+	cases := []struct {
+		name, key, plaintext, output string
+		ad                           []string
+	}{
+		{
+			// RFC 5297, A.1 (детерминированный режим)
+			name:      "A.1",
+			key:       "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			ad:        []string{"101112131415161718191a1b1c1d1e1f2021222324252627"},
+			plaintext: "112233445566778899aabbccddee",
+			output:    "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c",
+		},
+		{
+			// RFC 5297, A.2 (два компонента AD и nonce)
+			name: "A.2",
+			key:  "7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f",
+			ad: []string{
+				"00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100",
+				"102030405060708090a0",
+				"09f911029d74e35bd84156c5635688c0",
+			},
+			plaintext: "7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553",
+			output:    "7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d",
+		},
+	}
+
+	for _, c := range cases {
+		a, err := NewSIV(mustHex(t, c.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := a.(*siv)
+		var ad [][]byte
+		for _, h := range c.ad {
+			ad = append(ad, mustHex(t, h))
+		}
+
+		sealed := s.seal(nil, mustHex(t, c.plaintext), ad)
+		if got := hex.EncodeToString(sealed); got != c.output {
+			t.Fatalf("%s: seal mismatch:\ngot:  %s\nwant: %s", c.name, got, c.output)
+		}
+		opened, err := s.open(nil, sealed, ad)
+		if err != nil || hex.EncodeToString(opened) != c.plaintext {
+			t.Fatalf("%s: open failed: %v", c.name, err)
+		}
+
+		sealed[len(sealed)-1] ^= 0x01
+		if _, err := s.open(nil, sealed, ad); !errors.Is(err, ErrAuthFailed) {
+			t.Fatalf("%s: expected ErrAuthFailed, got %v", c.name, err)
+		}
+	}
+}
+
+func TestSIV_Deterministic(t *testing.T) {
+	a, err := NewAEAD("aes-256", "siv", bytes.Repeat([]byte{0x05}, 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aad := []byte("users.email")
+	x := a.Seal(nil, nil, []byte("alice@example.com"), aad)
+	y := a.Seal(nil, nil, []byte("alice@example.com"), aad)
+	if !bytes.Equal(x, y) {
+		t.Fatalf("equal inputs must give equal ciphertexts")
+	}
+	if bytes.Equal(x, a.Seal(nil, nil, []byte("alice@example.com"), []byte("users.name"))) {
+		t.Fatalf("AAD must change the ciphertext")
+	}
+	if _, err := a.Open(nil, nil, x, []byte("users.name")); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("expected ErrAuthFailed for wrong AAD, got %v", err)
+	}
+}
+
+// RFC 5297: пустая строка AD — полноценный компонент S2V, отличный от её отсутствия.
+func TestSIV_EmptyADComponent(t *testing.T) {
+	a, err := NewAEAD("aes-256", "siv", bytes.Repeat([]byte{0x05}, 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := a.(VectorAEAD)
+	p := []byte("alice@example.com")
+
+	none := a.Seal(nil, nil, p, nil)
+	empty := a.Seal(nil, nil, p, []byte{})
+	if bytes.Equal(none, empty) {
+		t.Fatalf("empty AD must differ from no AD")
+	}
+	if !bytes.Equal(empty, v.SealVector(nil, nil, p, [][]byte{{}})) {
+		t.Fatalf("Seal with empty aad must equal SealVector with one empty component")
+	}
+	if _, err := a.Open(nil, nil, empty, nil); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("expected ErrAuthFailed without the empty AD component, got %v", err)
+	}
+
+	ad := [][]byte{[]byte("header"), {}}
+	if bytes.Equal(v.SealVector(nil, nil, p, ad), v.SealVector(nil, nil, p, ad[:1])) {
+		t.Fatalf("trailing empty AD component must change the ciphertext")
+	}
+	if bytes.Equal(v.SealVector(nil, nil, p, [][]byte{[]byte("ab"), []byte("c")}), v.SealVector(nil, nil, p, [][]byte{[]byte("a"), []byte("bc")})) {
+		t.Fatalf("AD components must not be concatenated")
+	}
+	if got, err := v.OpenVector(nil, nil, v.SealVector(nil, nil, p, ad), ad); err != nil || !bytes.Equal(got, p) {
+		t.Fatalf("OpenVector failed: %v", err)
+	}
+}
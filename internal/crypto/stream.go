@@ -0,0 +1,154 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// NewEncryptWriter: потоковое шифрование ecb/cbc/cfb/cfb8/cfb1/ofb/ctr с постоянным расходом памяти.
+// Записанные данные шифруются и уходят в w; Close дописывает последний (дополненный) блок.
+// IV в w не пишется — его размещение решает вызывающий. Для ECB iv игнорируется.
+func NewEncryptWriter(mode string, key, iv []byte, w io.Writer) (io.WriteCloser, error) {
+	return newModeWriter("aes", mode, pkcs7Padding{}, key, iv, w, false)
+}
+
+// NewDecryptWriter: потоковое расшифрование. В ECB/CBC последний блок удерживается
+// до Close, чтобы снять PKCS#7; ошибка дополнения (ErrInvalidPadding) возвращается из Close.
+func NewDecryptWriter(mode string, key, iv []byte, w io.Writer) (io.WriteCloser, error) {
+	return newModeWriter("aes", mode, pkcs7Padding{}, key, iv, w, true)
+}
+
+// NewPaddedEncryptWriter / NewPaddedDecryptWriter: то же для любого блочного --algorithm
+// и с другой схемой дополнения для ecb/cbc; в cfb/ofb/ctr padding не используется.
+func NewPaddedEncryptWriter(algorithm, mode string, padding Padding, key, iv []byte, w io.Writer) (io.WriteCloser, error) {
+	return newModeWriter(algorithm, mode, padding, key, iv, w, false)
+}
+
+func NewPaddedDecryptWriter(algorithm, mode string, padding Padding, key, iv []byte, w io.Writer) (io.WriteCloser, error) {
+	return newModeWriter(algorithm, mode, padding, key, iv, w, true)
+}
+
+func newModeWriter(algorithm, mode string, padding Padding, key, iv []byte, w io.Writer, decrypt bool) (io.WriteCloser, error) {
+	block, err := NewBlockCipher(algorithm, key)
+	if err != nil {
+		return nil, err
+	}
+	if mode != "ecb" && len(iv) != BlockSize {
+		return nil, errors.New("IV must be 16 bytes")
+	}
+
+	switch mode {
+	case "ecb":
+		if decrypt {
+			return &blockModeWriter{w: w, mode: ecbDecrypter{block}, padding: padding, decrypt: true}, nil
+		}
+		return &blockModeWriter{w: w, mode: ecbEncrypter{block}, padding: padding}, nil
+	case "cbc":
+		if decrypt {
+			return &blockModeWriter{w: w, mode: newCBCDecrypter(block, iv), padding: padding, decrypt: true}, nil
+		}
+		return &blockModeWriter{w: w, mode: newCBCEncrypter(block, iv), padding: padding}, nil
+	case "cfb":
+		return &keyStreamWriter{w: w, stream: newCFBStream(block, iv, decrypt)}, nil
+	case "cfb8", "cfb1":
+		return &keyStreamWriter{w: w, stream: newCFBSegmentStream(block, iv, cfbSegmentBits[mode], decrypt)}, nil
+	case "ofb":
+		return &keyStreamWriter{w: w, stream: newOFBStream(block, iv)}, nil
+	case "ctr":
+		return &keyStreamWriter{w: w, stream: newCTRStream(block, iv, incrementCounter)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported mode for streaming: %s", mode)
+	}
+}
+
+// blockModeWriter: ECB/CBC с дополнением padding. В буфере остаётся меньше блока при
+// шифровании и ровно один блок при расшифровании.
+type blockModeWriter struct {
+	w       io.Writer
+	mode    blockMode
+	padding Padding
+	decrypt bool
+	buf     []byte
+	out     []byte
+	closed  bool
+}
+
+func (x *blockModeWriter) Write(p []byte) (int, error) {
+	if x.closed {
+		return 0, errors.New("write to closed cipher stream")
+	}
+	x.buf = append(x.buf, p...)
+
+	n := len(x.buf) / BlockSize * BlockSize
+	if x.decrypt && n == len(x.buf) {
+		n -= BlockSize // последний блок может содержать дополнение
+	}
+	if n <= 0 {
+		return len(p), nil
+	}
+
+	if cap(x.out) < n {
+		x.out = make([]byte, n)
+	}
+	out := x.out[:n]
+	x.mode.cryptBlocks(out, x.buf[:n])
+	rest := copy(x.buf, x.buf[n:])
+	x.buf = x.buf[:rest]
+
+	if _, err := x.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (x *blockModeWriter) Close() error {
+	if x.closed {
+		return nil
+	}
+	x.closed = true
+
+	if x.decrypt {
+		// пустой буфер — пустой шифртекст: допустим только для схем без обязательного дополнения
+		if len(x.buf)%BlockSize != 0 {
+			return errors.New("ciphertext length must be multiple of block size")
+		}
+		last := make([]byte, len(x.buf))
+		x.mode.cryptBlocks(last, x.buf)
+		unpadded, err := x.padding.Unpad(last, BlockSize)
+		if err != nil {
+			return err
+		}
+		_, err = x.w.Write(unpadded)
+		return err
+	}
+
+	padded, err := x.padding.Pad(x.buf, BlockSize)
+	if err != nil {
+		return err
+	}
+	x.mode.cryptBlocks(padded, padded)
+	_, err = x.w.Write(padded)
+	return err
+}
+
+// keyStreamWriter: CFB/OFB/CTR, без буферизации и дополнения.
+type keyStreamWriter struct {
+	w      io.Writer
+	stream keyStream
+	out    []byte
+}
+
+func (x *keyStreamWriter) Write(p []byte) (int, error) {
+	if cap(x.out) < len(p) {
+		x.out = make([]byte, len(p))
+	}
+	out := x.out[:len(p)]
+	x.stream.xorKeyStream(out, p)
+	if _, err := x.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (x *keyStreamWriter) Close() error { return nil }
@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+)
+
+// writeInPieces пишет данные кусками неровной длины, чтобы проверить состояние между вызовами.
+func writeInPieces(t *testing.T, w interface{ Write([]byte) (int, error) }, data []byte) {
+	t.Helper()
+	sizes := []int{1, 7, 16, 33, 5, 100}
+	for i := 0; len(data) > 0; i++ {
+		n := sizes[i%len(sizes)]
+		if n > len(data) {
+			n = len(data)
+		}
+		if _, err := w.Write(data[:n]); err != nil {
+			t.Fatal(err)
+		}
+		data = data[n:]
+	}
+}
+
+func TestStream_MatchesInMemory(t *testing.T) {
+	key := mustHex(t, "2b7e151628aed2a6abf7158809cf4f3c")
+	iv := mustHex(t, "000102030405060708090a0b0c0d0e0f")
+	block, _ := aes.NewCipher(key)
+
+	inMemory := map[string]func(cipherBlock, []byte, []byte) ([]byte, error){
+		"cbc": encryptCBC,
+		"cfb": encryptCFB,
+		"ofb": encryptOFB,
+		"ctr": encryptCTR,
+	}
+
+	for _, size := range []int{0, 1, 15, 16, 17, 255, 256, 1000} {
+		plaintext := bytes.Repeat([]byte{0xa5}, size)
+		for i := range plaintext {
+			plaintext[i] ^= byte(i)
+		}
+
+		for _, mode := range []string{"ecb", "cbc", "cfb", "ofb", "ctr"} {
+			var want []byte
+			var err error
+			if mode == "ecb" {
+				want, err = EncryptECB(key, plaintext)
+			} else {
+				want, err = inMemory[mode](block, iv, plaintext)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var enc bytes.Buffer
+			w, err := NewEncryptWriter(mode, key, iv, &enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeInPieces(t, w, plaintext)
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(enc.Bytes(), want) {
+				t.Fatalf("%s/%d: streaming ciphertext differs from in-memory", mode, size)
+			}
+
+			var dec bytes.Buffer
+			r, err := NewDecryptWriter(mode, key, iv, &dec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeInPieces(t, r, enc.Bytes())
+			if err := r.Close(); err != nil {
+				t.Fatalf("%s/%d: decrypt close: %v", mode, size, err)
+			}
+			if !bytes.Equal(dec.Bytes(), plaintext) {
+				t.Fatalf("%s/%d: streaming round trip mismatch", mode, size)
+			}
+		}
+	}
+}
+
+func TestStream_BadPadding(t *testing.T) {
+	key := mustHex(t, "2b7e151628aed2a6abf7158809cf4f3c")
+	iv := make([]byte, BlockSize)
+
+	var dec bytes.Buffer
+	w, _ := NewDecryptWriter("cbc", key, iv, &dec)
+	w.Write(make([]byte, 17))
+	if err := w.Close(); err == nil {
+		t.Fatalf("expected error for truncated CBC ciphertext")
+	}
+}
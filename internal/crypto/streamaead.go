@@ -0,0 +1,196 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Сегментированный AEAD (конструкция STREAM, Hoang et al. 2015).
+// Открытый текст режется на чанки по chunkSize байт, каждый запечатывается отдельно
+// с nonce = prefix || счётчик (32 бита, big-endian) || флаг последнего чанка (0/1).
+// Последний чанк может быть неполным (пустым — только для пустого входа).
+//
+// Перестановка чанков меняет счётчик, отбрасывание хвоста оставляет последним чанк
+// без флага, а вставка чанка из другого файла не проходит проверку из-за другого
+// prefix/ключа — во всех случаях Open вернёт ErrAuthFailed.
+//
+// Размер чанка читается из заголовка, поэтому он ограничен сверху: буфер чанка
+// выделяется целиком, и иначе файл мог бы потребовать до 4 ГиБ памяти.
+const (
+	DefaultStreamChunkSize = 64 * 1024
+	MinStreamChunkSize     = 1024
+	MaxStreamChunkSize     = 64 * 1024 * 1024
+	streamNonceSuffix      = 5 // счётчик (4) + флаг (1)
+)
+
+// StreamNoncePrefixSize: длина случайного prefix для данного AEAD (хранится в заголовке вместо IV).
+func StreamNoncePrefixSize(algorithm, mode string) int {
+	return IVSize(algorithm, mode) - streamNonceSuffix
+}
+
+// NewStreamSealer: записанный открытый текст уходит в w запечатанными чанками;
+// Close запечатывает последний чанк с флагом. aad добавляется к каждому чанку.
+func NewStreamSealer(aead AEAD, prefix, aad []byte, chunkSize int, w io.Writer) (io.WriteCloser, error) {
+	if err := checkStreamParams(aead, prefix, chunkSize); err != nil {
+		return nil, err
+	}
+	return &streamSealer{streamState: newStreamState(aead, prefix, aad, chunkSize, w)}, nil
+}
+
+// NewStreamOpener: обратная операция. Открытый текст чанка выдаётся в w только после
+// проверки его тега; обрыв потока обнаруживается в Close.
+func NewStreamOpener(aead AEAD, prefix, aad []byte, chunkSize int, w io.Writer) (io.WriteCloser, error) {
+	if err := checkStreamParams(aead, prefix, chunkSize); err != nil {
+		return nil, err
+	}
+	return &streamOpener{streamState: newStreamState(aead, prefix, aad, chunkSize, w)}, nil
+}
+
+func checkStreamParams(aead AEAD, prefix []byte, chunkSize int) error {
+	if len(prefix) != aead.NonceSize()-streamNonceSuffix {
+		return fmt.Errorf("stream nonce prefix must be %d bytes", aead.NonceSize()-streamNonceSuffix)
+	}
+	if chunkSize <= 0 || chunkSize > MaxStreamChunkSize {
+		return fmt.Errorf("stream chunk size must be between 1 and %d bytes", MaxStreamChunkSize)
+	}
+	return nil
+}
+
+type streamState struct {
+	aead      AEAD
+	nonce     []byte
+	aad       []byte
+	chunkSize int
+	w         io.Writer
+	counter   uint32
+	buf       []byte
+	out       []byte
+	closed    bool
+}
+
+func newStreamState(aead AEAD, prefix, aad []byte, chunkSize int, w io.Writer) streamState {
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, prefix)
+	return streamState{
+		aead:      aead,
+		nonce:     nonce,
+		aad:       append([]byte(nil), aad...),
+		chunkSize: chunkSize,
+		w:         w,
+	}
+}
+
+// chunkNonce заполняет счётчик и флаг в хвосте nonce.
+func (s *streamState) chunkNonce(last bool) ([]byte, error) {
+	if s.counter == math.MaxUint32 && !last {
+		return nil, errors.New("stream too long: chunk counter exhausted")
+	}
+	n := len(s.nonce)
+	binary.BigEndian.PutUint32(s.nonce[n-streamNonceSuffix:], s.counter)
+	s.nonce[n-1] = 0
+	if last {
+		s.nonce[n-1] = 1
+	}
+	return s.nonce, nil
+}
+
+// fill дописывает в buf не больше limit байт из p и возвращает остаток p.
+func (s *streamState) fill(p []byte, limit int) []byte {
+	k := limit - len(s.buf)
+	if k > len(p) {
+		k = len(p)
+	}
+	s.buf = append(s.buf, p[:k]...)
+	return p[k:]
+}
+
+type streamSealer struct {
+	streamState
+}
+
+func (s *streamSealer) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("write to closed cipher stream")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		// полный буфер запечатываем, только когда пришли ещё данные: он не последний
+		if len(s.buf) == s.chunkSize {
+			if err := s.sealChunk(false); err != nil {
+				return 0, err
+			}
+		}
+		p = s.fill(p, s.chunkSize)
+	}
+	return n, nil
+}
+
+func (s *streamSealer) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.sealChunk(true)
+}
+
+func (s *streamSealer) sealChunk(last bool) error {
+	nonce, err := s.chunkNonce(last)
+	if err != nil {
+		return err
+	}
+	s.out = s.aead.Seal(s.out[:0], nonce, s.buf, s.aad)
+	s.buf = s.buf[:0]
+	s.counter++
+	_, err = s.w.Write(s.out)
+	return err
+}
+
+type streamOpener struct {
+	streamState
+}
+
+func (s *streamOpener) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("write to closed cipher stream")
+	}
+	sealedSize := s.chunkSize + s.aead.Overhead()
+	n := len(p)
+	for len(p) > 0 {
+		if len(s.buf) == sealedSize {
+			if err := s.openChunk(false); err != nil {
+				return 0, err
+			}
+		}
+		p = s.fill(p, sealedSize)
+	}
+	return n, nil
+}
+
+func (s *streamOpener) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if len(s.buf) < s.aead.Overhead() {
+		return fmt.Errorf("truncated stream: %w", ErrAuthFailed)
+	}
+	return s.openChunk(true)
+}
+
+func (s *streamOpener) openChunk(last bool) error {
+	nonce, err := s.chunkNonce(last)
+	if err != nil {
+		return err
+	}
+	s.out, err = s.aead.Open(s.out[:0], nonce, s.buf, s.aad)
+	if err != nil {
+		return fmt.Errorf("chunk %d: %w", s.counter, err)
+	}
+	s.buf = s.buf[:0]
+	s.counter++
+	_, err = s.w.Write(s.out)
+	return err
+}
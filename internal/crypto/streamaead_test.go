@@ -0,0 +1,122 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+const testChunkSize = 64
+
+func streamSeal(t *testing.T, aead AEAD, prefix, plaintext []byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	w, err := NewStreamSealer(aead, prefix, []byte("header"), testChunkSize, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeInPieces(t, w, plaintext)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func streamOpen(aead AEAD, prefix, sealed []byte) ([]byte, error) {
+	var out bytes.Buffer
+	w, err := NewStreamOpener(aead, prefix, []byte("header"), testChunkSize, &out)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(sealed); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func streamAEADs(t *testing.T) map[string]AEAD {
+	key := bytes.Repeat([]byte{0x07}, 32)
+	gcm, err := NewAEAD("aes-256", "gcm", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chacha, _ := NewChaCha20Poly1305(key)
+	xchacha, _ := NewXChaCha20Poly1305(key)
+	return map[string]AEAD{"gcm": gcm, "chacha20-poly1305": chacha, "xchacha20-poly1305": xchacha}
+}
+
+func TestStreamAEAD_RoundTrip(t *testing.T) {
+	for name, aead := range streamAEADs(t) {
+		prefix := bytes.Repeat([]byte{0x01}, aead.NonceSize()-streamNonceSuffix)
+
+		for _, size := range []int{0, 1, testChunkSize - 1, testChunkSize, testChunkSize + 1, 3 * testChunkSize, 1000} {
+			plaintext := make([]byte, size)
+			for i := range plaintext {
+				plaintext[i] = byte(i * 7)
+			}
+
+			sealed := streamSeal(t, aead, prefix, plaintext)
+			chunks := (size + testChunkSize - 1) / testChunkSize
+			if chunks == 0 {
+				chunks = 1
+			}
+			if len(sealed) != size+chunks*aead.Overhead() {
+				t.Fatalf("%s/%d: unexpected sealed length %d", name, size, len(sealed))
+			}
+
+			got, err := streamOpen(aead, prefix, sealed)
+			if err != nil {
+				t.Fatalf("%s/%d: open: %v", name, size, err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Fatalf("%s/%d: round trip mismatch", name, size)
+			}
+		}
+	}
+}
+
+func TestStreamAEAD_DetectsTampering(t *testing.T) {
+	for name, aead := range streamAEADs(t) {
+		prefix := bytes.Repeat([]byte{0x01}, aead.NonceSize()-streamNonceSuffix)
+		other := bytes.Repeat([]byte{0x02}, len(prefix))
+		sealedChunk := testChunkSize + aead.Overhead()
+
+		plaintext := bytes.Repeat([]byte("0123456789abcdef"), 12) // 3 полных чанка
+		sealed := streamSeal(t, aead, prefix, plaintext)
+		foreign := streamSeal(t, aead, other, plaintext)
+
+		cases := map[string][]byte{
+			// отброшен последний чанк целиком
+			"truncated": sealed[:2*sealedChunk],
+			// отрезан кусок последнего чанка
+			"cut": sealed[:len(sealed)-5],
+			// чанки 0 и 1 переставлены
+			"reordered": append(append(append([]byte(nil), sealed[sealedChunk:2*sealedChunk]...), sealed[:sealedChunk]...), sealed[2*sealedChunk:]...),
+			// чанк 1 взят из другого файла
+			"spliced": append(append(append([]byte(nil), sealed[:sealedChunk]...), foreign[sealedChunk:2*sealedChunk]...), sealed[2*sealedChunk:]...),
+			// после последнего чанка дописаны данные
+			"extended": append(append([]byte(nil), sealed...), sealed[:sealedChunk]...),
+		}
+
+		for what, data := range cases {
+			if _, err := streamOpen(aead, prefix, data); !errors.Is(err, ErrAuthFailed) {
+				t.Fatalf("%s/%s: expected ErrAuthFailed, got %v", name, what, err)
+			}
+		}
+	}
+}
+
+func TestStreamAEAD_RejectsOversizedChunk(t *testing.T) {
+	aead := streamAEADs(t)["gcm"]
+	prefix := make([]byte, StreamNoncePrefixSize("aes-256", "gcm"))
+	if _, err := NewStreamOpener(aead, prefix, nil, MaxStreamChunkSize+1, io.Discard); err == nil {
+		t.Fatal("chunk size above MaxStreamChunkSize accepted")
+	}
+	if _, err := NewStreamOpener(aead, prefix, nil, MaxStreamChunkSize, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+}
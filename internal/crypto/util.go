@@ -2,19 +2,113 @@ package crypto
 
 import (
 	"crypto/aes"
+	"crypto/cipher"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
+
+	"cryptcore/internal/blockcipher"
 )
 
-const BlockSize = aes.BlockSize // 16 bytes for AES-128
+const BlockSize = aes.BlockSize // 16 bytes for every AES key size
+
+// cipherKeySizes: длина ключа в байтах для каждого --algorithm; "aes" — алиас aes-128.
+var cipherKeySizes = map[string]int{
+	"aes":                16,
+	"aes-128":            16,
+	"aes-192":            24,
+	"aes-256":            32,
+	"chacha20-poly1305":  ChaCha20KeySize,
+	"xchacha20-poly1305": ChaCha20KeySize,
+	"camellia-128":       16,
+	"camellia-192":       24,
+	"camellia-256":       32,
+	"aria-128":           16,
+	"aria-192":           24,
+	"aria-256":           32,
+	"serpent-128":        16,
+	"serpent-192":        24,
+	"serpent-256":        32,
+	"twofish-128":        16,
+	"twofish-192":        24,
+	"twofish-256":        32,
+	"sm4":                16,
+}
+
+// algorithmList — для сообщений об ошибках, в порядке справки.
+const algorithmList = "aes, aes-128, aes-192, aes-256, camellia-128/192/256, aria-128/192/256, " +
+	"serpent-128/192/256, twofish-128/192/256, sm4, chacha20-poly1305, xchacha20-poly1305"
+
+// KeySizeForAlgorithm возвращает длину ключа в байтах для --algorithm.
+func KeySizeForAlgorithm(algorithm string) (int, error) {
+	size, ok := cipherKeySizes[algorithm]
+	if !ok {
+		return 0, fmt.Errorf("unsupported algorithm %q (%s)", algorithm, algorithmList)
+	}
+	return size, nil
+}
+
+// IsAESAlgorithm: aes, aes-128, aes-192, aes-256.
+func IsAESAlgorithm(algorithm string) bool {
+	return algorithm == "aes" || strings.HasPrefix(algorithm, "aes-")
+}
+
+// CheckAlgorithmMode: siv (CMAC-AES) и xts (IEEE 1619) определены только для AES,
+// остальные блочные режимы работают с любым 128-битным шифром из реестра.
+func CheckAlgorithmMode(algorithm, mode string) error {
+	if (mode == "siv" || mode == "xts") && !IsAESAlgorithm(algorithm) {
+		return fmt.Errorf("%s is defined for AES only, not %s", mode, algorithm)
+	}
+	return nil
+}
+
+// blockCipherName: имя шифра в реестре blockcipher для --algorithm ("camellia-256" -> "camellia").
+// Длина ключа в имени алгоритма лишь задаёт размер ключа; сам шифр определяет её по ключу.
+func blockCipherName(algorithm string) string {
+	name, _, _ := strings.Cut(algorithm, "-")
+	return name
+}
+
+// NewBlockCipher создаёт 128-битный блочный шифр для --algorithm. AES идёт через
+// blockcipher.NewAES (реализация выбирается --aes-impl), остальные — из реестра.
+func NewBlockCipher(algorithm string, key []byte) (cipher.Block, error) {
+	if IsAESAlgorithm(algorithm) {
+		return blockcipher.NewAES(key)
+	}
+	if IsChaChaAlgorithm(algorithm) {
+		return nil, fmt.Errorf("%s is not a block cipher", algorithm)
+	}
+	block, err := blockcipher.New(blockCipherName(algorithm), key)
+	if err != nil {
+		return nil, err
+	}
+	if block.BlockSize() != BlockSize {
+		return nil, fmt.Errorf("%s: block size %d is not supported", algorithm, block.BlockSize())
+	}
+	return block, nil
+}
+
+// KeySize возвращает длину ключа для пары --algorithm/--mode:
+// SIV и XTS берут ключ двойной длины (CMAC || CTR и K1 || K2 соответственно).
+func KeySize(algorithm, mode string) (int, error) {
+	size, err := KeySizeForAlgorithm(algorithm)
+	if err != nil {
+		return 0, err
+	}
+	if mode == "siv" || mode == "xts" {
+		size *= 2
+	}
+	return size, nil
+}
 
-func ParseHexKey(hexKey string) ([]byte, error) {
+func ParseHexKey(hexKey string, keySize int) ([]byte, error) {
 	key, err := hex.DecodeString(hexKey)
 	if err != nil {
 		return nil, err
 	}
-	if len(key) != 16 {
-		return nil, errors.New("AES-128 key must be 16 bytes (32 hex chars)")
+	if len(key) != keySize {
+		return nil, fmt.Errorf("%d-bit key must be %d bytes (%d hex chars)", keySize*8, keySize, keySize*2)
 	}
 	return key, nil
 }
@@ -0,0 +1,108 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"cryptcore/internal/blockcipher"
+)
+
+func TestKeySizeForAlgorithm(t *testing.T) {
+	cases := map[string]int{"aes": 16, "aes-128": 16, "aes-192": 24, "aes-256": 32}
+	for name, want := range cases {
+		got, err := KeySizeForAlgorithm(name)
+		if err != nil || got != want {
+			t.Fatalf("%s: got %d, %v; want %d", name, got, err, want)
+		}
+	}
+	if _, err := KeySizeForAlgorithm("des"); err == nil {
+		t.Fatalf("expected error for unsupported algorithm")
+	}
+	if err := CheckAlgorithmMode("twofish-256", "xts"); err == nil {
+		t.Fatalf("expected error for twofish-256/xts")
+	}
+}
+
+func TestBlockCipherModes(t *testing.T) {
+	key := mustHex(t, "000102030405060708090a0b0c0d0e0f")
+	iv := mustHex(t, "f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext := []byte("The quick brown fox jumps over the lazy dog")
+
+	// openssl enc -camellia-128-cbc / -sm4-ctr с теми же key и iv
+	vectors := []struct{ algorithm, mode, ciphertext string }{
+		{"camellia-128", "cbc", "6bcb85ea02fa693c10c65a7758221fb159a0a3bdc197746e38567dd95afbbd7a7b92706d8d0d9ee506e372873cfae536"},
+		{"sm4", "ctr", "1a164c629006baf0ddaff2a60c223d1b10a00c953237abf15a458e147252c44e57e6f16979adcf1850c04a"},
+	}
+	for _, v := range vectors {
+		var enc bytes.Buffer
+		w, err := NewPaddedEncryptWriter(v.algorithm, v.mode, pkcs7Padding{}, key, iv, &enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(plaintext)
+		w.Close()
+		if got := hex.EncodeToString(enc.Bytes()); got != v.ciphertext {
+			t.Fatalf("%s/%s: got %s, want %s", v.algorithm, v.mode, got, v.ciphertext)
+		}
+	}
+
+	// GCM над любым шифром из реестра совпадает с crypto/cipher
+	for _, algorithm := range []string{"camellia-128", "aria-128", "serpent-128", "twofish-128", "sm4"} {
+		aead, err := NewAEAD(algorithm, "gcm", key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, _ := blockcipher.New(blockCipherName(algorithm), key)
+		std, _ := cipher.NewGCM(block)
+		nonce := iv[:GCMNonceSize]
+		if !bytes.Equal(aead.Seal(nil, nonce, plaintext, []byte("aad")), std.Seal(nil, nonce, plaintext, []byte("aad"))) {
+			t.Fatalf("%s/gcm differs from crypto/cipher", algorithm)
+		}
+	}
+}
+
+func TestParseHexKey_Lengths(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		if _, err := ParseHexKey(strings.Repeat("ab", size), size); err != nil {
+			t.Fatalf("size %d: unexpected error %v", size, err)
+		}
+		if _, err := ParseHexKey(strings.Repeat("ab", size-1), size); err == nil {
+			t.Fatalf("size %d: expected error for short key", size)
+		}
+	}
+}
+
+func TestModes_AllKeySizes(t *testing.T) {
+	plaintext := []byte("The quick brown fox jumps over the lazy dog")
+
+	for _, size := range []int{16, 24, 32} {
+		key := bytes.Repeat([]byte{0x42}, size)
+
+		for _, mode := range []string{"cbc", "cfb", "ofb", "ctr"} {
+			enc, err := EncryptWithIVMode(mode, key, plaintext)
+			if err != nil {
+				t.Fatalf("AES-%d %s encrypt: %v", size*8, mode, err)
+			}
+			dec, err := DecryptWithIVMode(mode, key, enc, "", false)
+			if err != nil {
+				t.Fatalf("AES-%d %s decrypt: %v", size*8, mode, err)
+			}
+			if !bytes.Equal(dec, plaintext) {
+				t.Fatalf("AES-%d %s round trip mismatch", size*8, mode)
+			}
+		}
+
+		// CTR сверяем с crypto/cipher
+		enc, _ := EncryptWithIVMode("ctr", key, plaintext)
+		block, _ := aes.NewCipher(key)
+		want := make([]byte, len(plaintext))
+		cipher.NewCTR(block, enc[:BlockSize]).XORKeyStream(want, plaintext)
+		if !bytes.Equal(enc[BlockSize:], want) {
+			t.Fatalf("AES-%d ctr differs from crypto/cipher", size*8)
+		}
+	}
+}
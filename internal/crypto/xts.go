@@ -0,0 +1,206 @@
+package crypto
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"cryptcore/internal/blockcipher"
+)
+
+// AES-XTS (IEEE 1619, NIST SP 800-38E) для образов дисков. Файл режется на секторы
+// по sectorSize байт; твик сектора — его номер (128 бит, little-endian), зашифрованный
+// ключом K2. Хвост сектора, не кратный блоку, шифруется с кражей шифртекста,
+// поэтому длина шифртекста равна длине открытого текста, IV не хранится,
+// а любой сектор расшифровывается отдельно.
+const (
+	DefaultXTSSectorSize = 512
+	MaxXTSSectorSize     = 1 << 20
+)
+
+// ErrXTSShortSector: последний сектор короче блока — кража шифртекста невозможна.
+var ErrXTSShortSector = errors.New("xts: data must be at least 16 bytes per sector")
+
+// ErrXTSEqualKeys: IEEE 1619-2018 и SP 800-38E требуют K1 != K2.
+var ErrXTSEqualKeys = errors.New("xts: the two key halves must differ")
+
+// XTS шифрует секторы независимо друг от друга.
+type XTS struct {
+	k1, k2 cipherBlock
+}
+
+// NewXTS принимает ключ двойной длины K1||K2: 32 или 64 байта (XTS-AES-128/256).
+// Ключ с равными половинами отвергается.
+func NewXTS(key []byte) (*XTS, error) {
+	if len(key) != 32 && len(key) != 64 {
+		return nil, errors.New("XTS-AES key must be 32 or 64 bytes")
+	}
+	half := len(key) / 2
+	if subtle.ConstantTimeCompare(key[:half], key[half:]) == 1 {
+		return nil, ErrXTSEqualKeys
+	}
+	return newXTS(key)
+}
+
+// newXTS без проверки K1 != K2 — только для вектора 1 IEEE 1619 с нулевым ключом.
+func newXTS(key []byte) (*XTS, error) {
+	half := len(key) / 2
+	k1, err := blockcipher.NewAES(key[:half])
+	if err != nil {
+		return nil, err
+	}
+	k2, err := blockcipher.NewAES(key[half:])
+	if err != nil {
+		return nil, err
+	}
+	return &XTS{k1: k1, k2: k2}, nil
+}
+
+// EncryptSector шифрует один сектор; len(src) >= 16, dst той же длины.
+func (x *XTS) EncryptSector(dst, src []byte, sector uint64) error {
+	return x.crypt(dst, src, sector, false)
+}
+
+// DecryptSector — обратная операция.
+func (x *XTS) DecryptSector(dst, src []byte, sector uint64) error {
+	return x.crypt(dst, src, sector, true)
+}
+
+func (x *XTS) crypt(dst, src []byte, sector uint64, decrypt bool) error {
+	if len(src) < BlockSize {
+		return ErrXTSShortSector
+	}
+	if len(dst) < len(src) {
+		return errors.New("xts: output smaller than input")
+	}
+
+	var tweak [BlockSize]byte
+	binary.LittleEndian.PutUint64(tweak[:8], sector)
+	x.k2.Encrypt(tweak[:], tweak[:])
+
+	full := len(src) / BlockSize
+	tail := len(src) % BlockSize
+	if tail != 0 {
+		full-- // последний полный блок участвует в краже
+	}
+	for i := 0; i < full; i++ {
+		off := i * BlockSize
+		x.cryptBlock(dst[off:off+BlockSize], src[off:off+BlockSize], &tweak, decrypt)
+		xtsMulAlpha(&tweak)
+	}
+	if tail == 0 {
+		return nil
+	}
+
+	// кража шифртекста (IEEE 1619, 5.3.2): для блоков m-1 и m нужны твики T_{m-1} и T_m,
+	// при расшифровании они применяются в обратном порядке
+	off := full * BlockSize
+	t1 := tweak
+	xtsMulAlpha(&tweak)
+	t2 := tweak
+	if decrypt {
+		t1, t2 = t2, t1
+	}
+	var cc [BlockSize]byte
+	x.cryptBlock(cc[:], src[off:off+BlockSize], &t1, decrypt)
+	var pp [BlockSize]byte
+	copy(pp[:], src[off+BlockSize:])
+	copy(pp[tail:], cc[tail:])
+	copy(dst[off+BlockSize:], cc[:tail])
+	x.cryptBlock(dst[off:off+BlockSize], pp[:], &t2, decrypt)
+	return nil
+}
+
+func (x *XTS) cryptBlock(dst, src []byte, tweak *[BlockSize]byte, decrypt bool) {
+	var b [BlockSize]byte
+	xorBlocks(b[:], src, tweak[:])
+	if decrypt {
+		x.k1.Decrypt(b[:], b[:])
+	} else {
+		x.k1.Encrypt(b[:], b[:])
+	}
+	xorBlocks(dst, b[:], tweak[:])
+}
+
+// xtsMulAlpha умножает твик на α в GF(2^128); в XTS блок little-endian,
+// поэтому перенос идёт от старшего бита байта 15 в байт 0.
+func xtsMulAlpha(t *[BlockSize]byte) {
+	carry := t[BlockSize-1] >> 7
+	for i := BlockSize - 1; i > 0; i-- {
+		t[i] = t[i]<<1 | t[i-1]>>7
+	}
+	t[0] = t[0]<<1 ^ 0x87*carry
+}
+
+// NewXTSWriter: записанные данные уходят в w посекторно, начиная с сектора 0;
+// Close обрабатывает последний неполный сектор.
+func NewXTSWriter(key []byte, sectorSize int, decrypt bool, w io.Writer) (io.WriteCloser, error) {
+	if sectorSize < BlockSize || sectorSize > MaxXTSSectorSize || sectorSize%BlockSize != 0 {
+		return nil, fmt.Errorf("xts: sector size must be a multiple of 16 between 16 and %d", MaxXTSSectorSize)
+	}
+	x, err := NewXTS(key)
+	if err != nil {
+		return nil, err
+	}
+	return &xtsWriter{
+		xts:     x,
+		decrypt: decrypt,
+		w:       w,
+		buf:     make([]byte, 0, sectorSize),
+		out:     make([]byte, sectorSize),
+	}, nil
+}
+
+type xtsWriter struct {
+	xts     *XTS
+	decrypt bool
+	w       io.Writer
+	sector  uint64
+	buf     []byte
+	out     []byte
+	closed  bool
+}
+
+func (s *xtsWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("write to closed cipher stream")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		k := copy(s.buf[len(s.buf):cap(s.buf)], p)
+		s.buf = s.buf[:len(s.buf)+k]
+		p = p[k:]
+		// полный сектор не зависит от следующих данных — обрабатываем сразу
+		if len(s.buf) == cap(s.buf) {
+			if err := s.flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (s *xtsWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if len(s.buf) == 0 {
+		return nil
+	}
+	return s.flush()
+}
+
+func (s *xtsWriter) flush() error {
+	if err := s.xts.crypt(s.out, s.buf, s.sector, s.decrypt); err != nil {
+		return fmt.Errorf("sector %d: %w", s.sector, err)
+	}
+	if _, err := s.w.Write(s.out[:len(s.buf)]); err != nil {
+		return err
+	}
+	s.sector++
+	s.buf = s.buf[:0]
+	return nil
+}
@@ -0,0 +1,155 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// IEEE 1619-2007, приложение B: векторы 1-3 (полные блоки) и 15-18 (кража шифртекста).
+// Номер сектора в стандарте записан байтами little-endian: "9a78563412" = 0x123456789a.
+// У вектора 1 K1 == K2, поэтому векторы проверяются через newXTS.
+var xtsVectors = []struct {
+	name, key  string
+	sector     uint64
+	plaintext  string
+	ciphertext string
+}{
+	{
+		name:       "1",
+		key:        "0000000000000000000000000000000000000000000000000000000000000000",
+		plaintext:  "0000000000000000000000000000000000000000000000000000000000000000",
+		ciphertext: "917cf69ebd68b2ec9b9fe9a3eadda692cd43d2f59598ed858c02c2652fbf922e",
+	},
+	{
+		name:       "2",
+		key:        "1111111111111111111111111111111122222222222222222222222222222222",
+		sector:     0x3333333333,
+		plaintext:  "4444444444444444444444444444444444444444444444444444444444444444",
+		ciphertext: "c454185e6a16936e39334038acef838bfb186fff7480adc4289382ecd6d394f0",
+	},
+	{
+		name:       "3",
+		key:        "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f022222222222222222222222222222222",
+		sector:     0x3333333333,
+		plaintext:  "4444444444444444444444444444444444444444444444444444444444444444",
+		ciphertext: "af85336b597afc1a900b2eb21ec949d292df4c047e0b21532186a5971a227a89",
+	},
+	{
+		name:       "15",
+		key:        "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+		sector:     0x123456789a,
+		plaintext:  "000102030405060708090a0b0c0d0e0f10",
+		ciphertext: "6c1625db4671522d3d7599601de7ca09ed",
+	},
+	{
+		name:       "16",
+		key:        "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+		sector:     0x123456789a,
+		plaintext:  "000102030405060708090a0b0c0d0e0f1011",
+		ciphertext: "d069444b7a7e0cab09e24447d24deb1fedbf",
+	},
+	{
+		name:       "17",
+		key:        "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+		sector:     0x123456789a,
+		plaintext:  "000102030405060708090a0b0c0d0e0f101112",
+		ciphertext: "e5df1351c0544ba1350b3363cd8ef4beedbf9d",
+	},
+	{
+		name:       "18",
+		key:        "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+		sector:     0x123456789a,
+		plaintext:  "000102030405060708090a0b0c0d0e0f10111213",
+		ciphertext: "9d84c813f719aa2c7be3f66171c7c5c2edbf9dac",
+	},
+}
+
+func TestXTS_IEEE1619Vectors(t *testing.T) {
+	for _, v := range xtsVectors {
+		x, err := newXTS(mustHex(t, v.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		plaintext := mustHex(t, v.plaintext)
+		out := make([]byte, len(plaintext))
+		if err := x.EncryptSector(out, plaintext, v.sector); err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(out); got != v.ciphertext {
+			t.Fatalf("vector %s: encrypt mismatch:\ngot:  %s\nwant: %s", v.name, got, v.ciphertext)
+		}
+		if err := x.DecryptSector(out, out, v.sector); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, plaintext) {
+			t.Fatalf("vector %s: decrypt mismatch: got %x", v.name, out)
+		}
+	}
+}
+
+func TestXTSWriter_SectorsAreIndependent(t *testing.T) {
+	key := append(bytes.Repeat([]byte{0x09}, 32), bytes.Repeat([]byte{0x0a}, 32)...)
+	const sectorSize = 64
+	plaintext := make([]byte, 3*sectorSize+40) // последний сектор неполный
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	var enc bytes.Buffer
+	w, err := NewXTSWriter(key, sectorSize, false, &enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeInPieces(t, w, plaintext)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if enc.Len() != len(plaintext) {
+		t.Fatalf("ciphertext length %d, want %d", enc.Len(), len(plaintext))
+	}
+
+	// сектор 2 расшифровывается сам по себе
+	x, _ := NewXTS(key)
+	sector := make([]byte, sectorSize)
+	if err := x.DecryptSector(sector, enc.Bytes()[2*sectorSize:3*sectorSize], 2); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sector, plaintext[2*sectorSize:3*sectorSize]) {
+		t.Fatalf("sector 2 mismatch")
+	}
+
+	var dec bytes.Buffer
+	w, _ = NewXTSWriter(key, sectorSize, true, &dec)
+	writeInPieces(t, w, enc.Bytes())
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dec.Bytes(), plaintext) {
+		t.Fatalf("round trip mismatch")
+	}
+}
+
+func TestXTSWriter_ShortTail(t *testing.T) {
+	key := append(bytes.Repeat([]byte{0x01}, 16), bytes.Repeat([]byte{0x02}, 16)...)
+	w, err := NewXTSWriter(key, 32, false, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(make([]byte, 32+5))
+	if err := w.Close(); !errors.Is(err, ErrXTSShortSector) {
+		t.Fatalf("expected ErrXTSShortSector, got %v", err)
+	}
+}
+
+func TestXTS_RejectsEqualKeyHalves(t *testing.T) {
+	for _, size := range []int{32, 64} {
+		if _, err := NewXTS(bytes.Repeat([]byte{0x5c}, size)); !errors.Is(err, ErrXTSEqualKeys) {
+			t.Fatalf("%d-byte key with K1 == K2: expected ErrXTSEqualKeys, got %v", size, err)
+		}
+		if _, err := NewXTSWriter(make([]byte, size), DefaultXTSSectorSize, false, &bytes.Buffer{}); !errors.Is(err, ErrXTSEqualKeys) {
+			t.Fatalf("%d-byte zero key: expected ErrXTSEqualKeys, got %v", size, err)
+		}
+	}
+}
@@ -22,6 +22,37 @@ func WriteAll(path string, data []byte) error {
 	return nil
 }
 
+// Open открывает файл для потокового чтения.
+func Open(path string) (*os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Create создаёт (или обрезает) файл для потоковой записи.
+func Create(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// SameFile сообщает, указывают ли пути на один существующий файл.
+func SameFile(a, b string) bool {
+	sa, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	sb, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(sa, sb)
+}
+
 func DefaultEncryptedName(input string) string {
 	return input + ".enc"
 }
@@ -0,0 +1,156 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WalkOptions: правила сбора файлов для dgst по списку путей.
+type WalkOptions struct {
+	Recursive      bool     // обходить каталоги
+	FollowSymlinks bool     // при обходе заходить по символьным ссылкам (иначе они пропускаются)
+	Hidden         bool     // при обходе брать имена, начинающиеся с точки
+	Exclude        []string // шаблоны filepath.Match для имени или пути относительно аргумента
+}
+
+// WalkEntry: файл для хеширования либо ошибка, относящаяся к этому месту списка.
+type WalkEntry struct {
+	Path string
+	Err  error
+}
+
+// CollectFiles раскрывает аргументы в упорядоченный список файлов. Шаблоны (*, ?, [...])
+// раскрываются здесь же — на случай, если оболочка их не раскрыла; аргумент, который сам
+// называет существующий путь (например, "report[1].txt"), шаблоном не считается. Каталоги
+// обходятся в порядке имён, поэтому вывод не зависит от файловой системы. Явно названные
+// пути, в том числе ссылки и скрытые файлы, берутся всегда; фильтры (--exclude, --hidden,
+// --symlinks) действуют только на найденное при обходе и раскрытии шаблонов.
+func CollectFiles(args []string, o WalkOptions) []WalkEntry {
+	var out []WalkEntry
+	for _, arg := range args {
+		if _, err := os.Lstat(arg); err == nil || !strings.ContainsAny(arg, "*?[") {
+			out = collectPath(out, arg, o)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			out = append(out, WalkEntry{Path: arg, Err: fmt.Errorf("bad pattern: %w", err)})
+			continue
+		}
+		if len(matches) == 0 {
+			out = append(out, WalkEntry{Path: arg, Err: errors.New("no files match the pattern")})
+			continue
+		}
+		// как в оболочке: "*" не раскрывается в скрытые имена, ".*" — раскрывается
+		hidden := o.Hidden || strings.HasPrefix(filepath.Base(arg), ".")
+		for _, m := range matches {
+			if !o.excluded(m, filepath.Base(m)) && (hidden || !isHidden(filepath.Base(m))) && !o.skippedLink(m) {
+				out = collectPath(out, m, o)
+			}
+		}
+	}
+	return out
+}
+
+func collectPath(out []WalkEntry, path string, o WalkOptions) []WalkEntry {
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		return append(out, WalkEntry{Path: path, Err: err})
+	case !info.IsDir():
+		return append(out, WalkEntry{Path: path})
+	case !o.Recursive:
+		return append(out, WalkEntry{Path: path, Err: errors.New("is a directory (use -r)")})
+	}
+	w := walker{o: o, root: path, ancestors: map[string]bool{}}
+	return w.walk(out, path)
+}
+
+type walker struct {
+	o         WalkOptions
+	root      string
+	ancestors map[string]bool // реальные пути каталогов на текущем пути — защита от циклов ссылок
+}
+
+func realPath(dir string) string {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		real = dir
+	}
+	if abs, err := filepath.Abs(real); err == nil {
+		real = abs
+	}
+	return real
+}
+
+func (w *walker) walk(out []WalkEntry, dir string) []WalkEntry {
+	real := realPath(dir)
+	if w.ancestors[real] {
+		return out // ссылка на каталог выше по пути
+	}
+	w.ancestors[real] = true
+	defer delete(w.ancestors, real)
+
+	entries, err := os.ReadDir(dir) // уже отсортированы по имени
+	if err != nil {
+		return append(out, WalkEntry{Path: dir, Err: err})
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		rel, _ := filepath.Rel(w.root, path)
+		if (!w.o.Hidden && isHidden(e.Name())) || w.o.excluded(rel, e.Name()) {
+			continue
+		}
+
+		isDir := e.IsDir()
+		if e.Type()&os.ModeSymlink != 0 {
+			if !w.o.FollowSymlinks {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				out = append(out, WalkEntry{Path: path, Err: err})
+				continue
+			}
+			isDir = info.IsDir()
+		} else if !e.Type().IsRegular() && !isDir {
+			continue // сокеты, устройства, FIFO
+		}
+
+		if isDir {
+			out = w.walk(out, path)
+			continue
+		}
+		out = append(out, WalkEntry{Path: path})
+	}
+	return out
+}
+
+func (o WalkOptions) excluded(rel, name string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range o.Exclude {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// skippedLink: path — символьная ссылка, а ссылки не обходятся (--symlinks skip).
+func (o WalkOptions) skippedLink(path string) bool {
+	if o.FollowSymlinks {
+		return false
+	}
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
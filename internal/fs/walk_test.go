@@ -0,0 +1,87 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCollectFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"b.txt", "a.log", "sub/c.txt", ".hidden", ".git/config", "other/o.txt"} {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(root, "other"), filepath.Join(root, "sub", "link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	os.Symlink(root, filepath.Join(root, "sub", "loop")) // цикл: обход не должен зациклиться
+
+	rel := func(entries []WalkEntry) []string {
+		var paths []string
+		for _, e := range entries {
+			if e.Err != nil {
+				t.Fatalf("%s: %v", e.Path, e.Err)
+			}
+			r, _ := filepath.Rel(root, e.Path)
+			paths = append(paths, filepath.ToSlash(r))
+		}
+		return paths
+	}
+
+	cases := []struct {
+		o    WalkOptions
+		want []string
+	}{
+		{WalkOptions{Recursive: true}, []string{"a.log", "b.txt", "other/o.txt", "sub/c.txt"}},
+		{WalkOptions{Recursive: true, Hidden: true, Exclude: []string{"*.log", "other"}},
+			[]string{".git/config", ".hidden", "b.txt", "sub/c.txt"}},
+		{WalkOptions{Recursive: true, FollowSymlinks: true, Exclude: []string{"sub/c.txt"}},
+			[]string{"a.log", "b.txt", "other/o.txt", "sub/link/o.txt"}},
+	}
+	for i, c := range cases {
+		if got := rel(CollectFiles([]string{root}, c.o)); !slices.Equal(got, c.want) {
+			t.Errorf("case %d: got %v, want %v", i, got, c.want)
+		}
+	}
+
+	if got := rel(CollectFiles([]string{filepath.Join(root, "*.*")}, WalkOptions{})); !slices.Equal(got, []string{"a.log", "b.txt"}) {
+		t.Errorf("glob: got %v", got)
+	}
+	// ссылки из шаблона подчиняются --symlinks так же, как при обходе; явно названные берутся
+	os.Symlink(filepath.Join(root, "b.txt"), filepath.Join(root, "sub", "b-link.txt"))
+	pattern := filepath.Join(root, "sub", "*.txt")
+	if got := rel(CollectFiles([]string{pattern}, WalkOptions{})); !slices.Equal(got, []string{"sub/c.txt"}) {
+		t.Errorf("glob skipping links: got %v", got)
+	}
+	if got := rel(CollectFiles([]string{pattern}, WalkOptions{FollowSymlinks: true})); !slices.Equal(got, []string{"sub/b-link.txt", "sub/c.txt"}) {
+		t.Errorf("glob following links: got %v", got)
+	}
+	if got := rel(CollectFiles([]string{filepath.Join(root, "sub", "b-link.txt")}, WalkOptions{})); !slices.Equal(got, []string{"sub/b-link.txt"}) {
+		t.Errorf("explicit link: got %v", got)
+	}
+	entries := CollectFiles([]string{root, filepath.Join(root, "missing")}, WalkOptions{})
+	if len(entries) != 2 || entries[0].Err == nil || entries[1].Err == nil {
+		t.Errorf("directory without -r and missing file must be reported: %+v", entries)
+	}
+
+	// существующее имя со скобками берётся как есть, а не как шаблон [1] → "report1.txt"
+	dir := t.TempDir()
+	for _, name := range []string{"report[1].txt", "report1.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	literal := filepath.Join(dir, "report[1].txt")
+	if got := CollectFiles([]string{literal}, WalkOptions{}); len(got) != 1 || got[0].Path != literal || got[0].Err != nil {
+		t.Errorf("literal name with brackets: got %+v", got)
+	}
+	os.Remove(literal)
+	if got := CollectFiles([]string{literal}, WalkOptions{}); len(got) != 1 || got[0].Path != filepath.Join(dir, "report1.txt") {
+		t.Errorf("missing name is a pattern: got %+v", got)
+	}
+}
@@ -0,0 +1,280 @@
+package hash
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// BLAKE2b и BLAKE2s (RFC 7693): 64- и 32-битные варианты одной конструкции. Ключ, соль
+// и персонализация входят в блок параметров; ключ дополнительно подаётся первым блоком
+// данных, поэтому ключевой BLAKE2 — готовый MAC без HMAC.
+
+// Blake2Params: параметры BLAKE2. Size — длина дайджеста в байтах (1..64 для b, 1..32 для s),
+// Key — до Size-максимума байт, Salt и Personal — до 16 (b) или 8 (s) байт.
+type Blake2Params struct {
+	Size     int
+	Key      []byte
+	Salt     []byte
+	Personal []byte
+}
+
+var errBlake2Param = errors.New("invalid BLAKE2 parameters")
+
+// blake2Sigma: перестановки сообщения по раундам; раунды 10 и 11 BLAKE2b повторяют 0 и 1.
+var blake2Sigma = [10][16]byte{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// blake2sIV совпадает с начальными значениями SHA-256, blake2b — с SHA-512 (iv512).
+var blake2sIV = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
+	0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+func checkBlake2Params(p *Blake2Params, maxSize, saltSize int) error {
+	if p.Size < 1 || p.Size > maxSize || len(p.Key) > maxSize ||
+		len(p.Salt) > saltSize || len(p.Personal) > saltSize {
+		return fmt.Errorf("%w: size 1..%d, key up to %d bytes, salt and personalization up to %d bytes",
+			errBlake2Param, maxSize, maxSize, saltSize)
+	}
+	return nil
+}
+
+// DigestBLAKE2b: BLAKE2b как hash.Hash.
+type DigestBLAKE2b struct {
+	h      [8]uint64
+	t      [2]uint64 // счётчик байт (128 бит)
+	x      [128]byte
+	nx     int
+	params Blake2Params
+	init   [8]uint64 // h после блока параметров — для Reset
+}
+
+func NewBLAKE2b(p Blake2Params) (*DigestBLAKE2b, error) {
+	if err := checkBlake2Params(&p, 64, 16); err != nil {
+		return nil, err
+	}
+	d := &DigestBLAKE2b{params: p}
+	var salt, personal [16]byte
+	copy(salt[:], p.Salt)
+	copy(personal[:], p.Personal)
+	d.init = iv512
+	d.init[0] ^= 0x01010000 ^ uint64(len(p.Key))<<8 ^ uint64(p.Size)
+	d.init[4] ^= binary.LittleEndian.Uint64(salt[0:])
+	d.init[5] ^= binary.LittleEndian.Uint64(salt[8:])
+	d.init[6] ^= binary.LittleEndian.Uint64(personal[0:])
+	d.init[7] ^= binary.LittleEndian.Uint64(personal[8:])
+	d.Reset()
+	return d, nil
+}
+
+func (d *DigestBLAKE2b) Reset() {
+	d.h = d.init
+	d.t = [2]uint64{}
+	d.nx = 0
+	if len(d.params.Key) > 0 {
+		d.x = [128]byte{}
+		copy(d.x[:], d.params.Key)
+		d.nx = 128
+	}
+}
+
+// Write: полный буфер сжимается только при поступлении новых данных — последний блок
+// обрабатывается в Sum с флагом завершения.
+func (d *DigestBLAKE2b) Write(p []byte) (int, error) {
+	nn := len(p)
+	for len(p) > 0 {
+		if d.nx == 128 {
+			d.compress(false)
+			d.nx = 0
+		}
+		k := copy(d.x[d.nx:], p)
+		d.nx += k
+		p = p[k:]
+	}
+	return nn, nil
+}
+
+func (d *DigestBLAKE2b) compress(last bool) {
+	d.t[0] += uint64(d.nx)
+	if d.t[0] < uint64(d.nx) {
+		d.t[1]++
+	}
+	var m [16]uint64
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(d.x[8*i:])
+	}
+	var v [16]uint64
+	copy(v[:8], d.h[:])
+	copy(v[8:], iv512[:])
+	v[12] ^= d.t[0]
+	v[13] ^= d.t[1]
+	if last {
+		v[14] = ^v[14]
+	}
+	for r := 0; r < 12; r++ {
+		s := &blake2Sigma[r%10]
+		blake2bG(&v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+		blake2bG(&v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+		blake2bG(&v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+		blake2bG(&v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+		blake2bG(&v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+		blake2bG(&v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+		blake2bG(&v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+		blake2bG(&v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+	for i := range d.h {
+		d.h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+func blake2bG(v *[16]uint64, a, b, c, d int, x, y uint64) {
+	v[a] += v[b] + x
+	v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+	v[a] += v[b] + y
+	v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+}
+
+func (d *DigestBLAKE2b) Sum(b []byte) []byte {
+	d0 := *d // Копируем состояние, чтобы не портить текущее
+	for i := d0.nx; i < 128; i++ {
+		d0.x[i] = 0
+	}
+	d0.compress(true)
+	var digest [64]byte
+	for i, v := range d0.h {
+		binary.LittleEndian.PutUint64(digest[8*i:], v)
+	}
+	return append(b, digest[:d.params.Size]...)
+}
+
+func (d *DigestBLAKE2b) Size() int      { return d.params.Size }
+func (d *DigestBLAKE2b) BlockSize() int { return 128 }
+
+// DigestBLAKE2s: BLAKE2s как hash.Hash.
+type DigestBLAKE2s struct {
+	h      [8]uint32
+	t      [2]uint32 // счётчик байт (64 бита)
+	x      [64]byte
+	nx     int
+	params Blake2Params
+	init   [8]uint32
+}
+
+func NewBLAKE2s(p Blake2Params) (*DigestBLAKE2s, error) {
+	if err := checkBlake2Params(&p, 32, 8); err != nil {
+		return nil, err
+	}
+	d := &DigestBLAKE2s{params: p}
+	var salt, personal [8]byte
+	copy(salt[:], p.Salt)
+	copy(personal[:], p.Personal)
+	d.init = blake2sIV
+	d.init[0] ^= 0x01010000 ^ uint32(len(p.Key))<<8 ^ uint32(p.Size)
+	d.init[4] ^= binary.LittleEndian.Uint32(salt[0:])
+	d.init[5] ^= binary.LittleEndian.Uint32(salt[4:])
+	d.init[6] ^= binary.LittleEndian.Uint32(personal[0:])
+	d.init[7] ^= binary.LittleEndian.Uint32(personal[4:])
+	d.Reset()
+	return d, nil
+}
+
+func (d *DigestBLAKE2s) Reset() {
+	d.h = d.init
+	d.t = [2]uint32{}
+	d.nx = 0
+	if len(d.params.Key) > 0 {
+		d.x = [64]byte{}
+		copy(d.x[:], d.params.Key)
+		d.nx = 64
+	}
+}
+
+func (d *DigestBLAKE2s) Write(p []byte) (int, error) {
+	nn := len(p)
+	for len(p) > 0 {
+		if d.nx == 64 {
+			d.compress(false)
+			d.nx = 0
+		}
+		k := copy(d.x[d.nx:], p)
+		d.nx += k
+		p = p[k:]
+	}
+	return nn, nil
+}
+
+func (d *DigestBLAKE2s) compress(last bool) {
+	d.t[0] += uint32(d.nx)
+	if d.t[0] < uint32(d.nx) {
+		d.t[1]++
+	}
+	var m [16]uint32
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint32(d.x[4*i:])
+	}
+	var v [16]uint32
+	copy(v[:8], d.h[:])
+	copy(v[8:], blake2sIV[:])
+	v[12] ^= d.t[0]
+	v[13] ^= d.t[1]
+	if last {
+		v[14] = ^v[14]
+	}
+	for r := 0; r < 10; r++ {
+		s := &blake2Sigma[r]
+		blake2sG(&v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+		blake2sG(&v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+		blake2sG(&v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+		blake2sG(&v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+		blake2sG(&v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+		blake2sG(&v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+		blake2sG(&v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+		blake2sG(&v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+	for i := range d.h {
+		d.h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+func blake2sG(v *[16]uint32, a, b, c, d int, x, y uint32) {
+	v[a] += v[b] + x
+	v[d] = bits.RotateLeft32(v[d]^v[a], -16)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft32(v[b]^v[c], -12)
+	v[a] += v[b] + y
+	v[d] = bits.RotateLeft32(v[d]^v[a], -8)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft32(v[b]^v[c], -7)
+}
+
+func (d *DigestBLAKE2s) Sum(b []byte) []byte {
+	d0 := *d // Копируем состояние, чтобы не портить текущее
+	for i := d0.nx; i < 64; i++ {
+		d0.x[i] = 0
+	}
+	d0.compress(true)
+	var digest [32]byte
+	for i, v := range d0.h {
+		binary.LittleEndian.PutUint32(digest[4*i:], v)
+	}
+	return append(b, digest[:d.params.Size]...)
+}
+
+func (d *DigestBLAKE2s) Size() int      { return d.params.Size }
+func (d *DigestBLAKE2s) BlockSize() int { return 64 }
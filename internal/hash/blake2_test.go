@@ -0,0 +1,152 @@
+package hash
+
+import (
+	"encoding/hex"
+	"errors"
+	stdhash "hash"
+	"testing"
+)
+
+func TestBLAKE2_RFC7693(t *testing.T) {
+	// Приложения A и B RFC 7693: BLAKE2b-512("abc") и BLAKE2s-256("abc")
+	vectors := map[string]string{
+		"blake2b-512": "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923",
+		"blake2s-256": "508c5e8c327c14e2e1a72ba34eeb452f37458b209ed63a294d999b4c86675982",
+	}
+	for name, want := range vectors {
+		newHash, err := Digest(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := newHash()
+		h.Write([]byte("abc"))
+		if got := hex.EncodeToString(h.Sum(nil)); got != want {
+			t.Errorf("%s: got %s, want %s", name, got, want)
+		}
+	}
+}
+
+// blake2SelfTestSeq — генератор данных из приложения E RFC 7693.
+func blake2SelfTestSeq(n int, seed uint32) []byte {
+	out := make([]byte, n)
+	a, b := 0xDEAD4BAD*seed, uint32(1)
+	for i := range out {
+		t := a + b
+		a, b = b, t
+		out[i] = byte(t >> 24)
+	}
+	return out
+}
+
+// TestBLAKE2_SelfTest: «большой хеш» приложения E — все длины дайджеста, с ключом и без.
+func TestBLAKE2_SelfTest(t *testing.T) {
+	cases := []struct {
+		name     string
+		mdLens   []int
+		inLens   []int
+		newHash  func(Blake2Params) (stdhash.Hash, error)
+		expected string
+	}{
+		{
+			"blake2b", []int{20, 32, 48, 64}, []int{0, 3, 128, 129, 255, 1024},
+			func(p Blake2Params) (stdhash.Hash, error) { return NewBLAKE2b(p) },
+			"c23a7800d98123bd10f506c61e29da5603d763b8bbad2e737f5e765a7bccd475",
+		},
+		{
+			"blake2s", []int{16, 20, 28, 32}, []int{0, 3, 64, 65, 255, 1024},
+			func(p Blake2Params) (stdhash.Hash, error) { return NewBLAKE2s(p) },
+			"6a411f08ce25adcdfb02aba641451cec53c598b24f4fc787fbdc88797f4c1dfe",
+		},
+	}
+	for _, c := range cases {
+		total, _ := c.newHash(Blake2Params{Size: 32})
+		for _, outLen := range c.mdLens {
+			for _, inLen := range c.inLens {
+				in := blake2SelfTestSeq(inLen, uint32(inLen))
+				h, _ := c.newHash(Blake2Params{Size: outLen})
+				h.Write(in)
+				total.Write(h.Sum(nil))
+
+				key := blake2SelfTestSeq(outLen, uint32(outLen))
+				h, _ = c.newHash(Blake2Params{Size: outLen, Key: key})
+				h.Write(in)
+				total.Write(h.Sum(nil))
+			}
+		}
+		if got := hex.EncodeToString(total.Sum(nil)); got != c.expected {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.expected)
+		}
+	}
+}
+
+func TestBLAKE2_SaltPersonal(t *testing.T) {
+	// значения получены hashlib.blake2b/blake2s с теми же параметрами
+	b, err := NewBLAKE2b(Blake2Params{Size: 32, Key: []byte("key"), Salt: []byte("saltsaltsaltsalt"), Personal: []byte("cryptcore-v1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewBLAKE2s(Blake2Params{Size: 20, Key: []byte("key"), Salt: []byte("saltsalt"), Personal: []byte("cc-v1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors := []struct {
+		h    stdhash.Hash
+		want string
+	}{
+		{b, "f1e79d622706d5345986211caa581fa728896594922e7894517c5d88bffb200c"},
+		{s, "74fac83518c335fee41d23c4bf8faddc8424d513"},
+	}
+	for i, v := range vectors {
+		// дважды: Reset должен вернуть ключевой первый блок
+		for round := 0; round < 2; round++ {
+			v.h.Reset()
+			v.h.Write([]byte("abc"))
+			if got := hex.EncodeToString(v.h.Sum(nil)); got != v.want {
+				t.Errorf("vector %d, round %d: got %s, want %s", i, round, got, v.want)
+			}
+		}
+	}
+
+	if _, err := NewBLAKE2s(Blake2Params{Size: 32, Salt: make([]byte, 9)}); err == nil {
+		t.Fatal("expected error for 9-byte BLAKE2s salt")
+	}
+	if _, err := NewBLAKE2b(Blake2Params{Size: 65}); err == nil {
+		t.Fatal("expected error for 65-byte BLAKE2b digest")
+	}
+}
+
+func TestBLAKE2_UnevenWrites(t *testing.T) {
+	// целое число блоков: последний полный блок должен остаться в буфере до Sum
+	data := make([]byte, 1280)
+	vectors := map[string]string{
+		"blake2b-512": "a86b784c748f990b998e6d30d71e20cc95228d2b08dd85e29f63e4de8d8839bdf935f4291537af5014fe44c0b578a073e4c9217c7b05542d0c450784c30bac8a",
+		"blake2s-256": "52ea240758fbbcd27c605dfb70ef0957c9009335d6d0a8d60d219e611ba2e23f",
+	}
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for name, want := range vectors {
+		newHash, _ := Digest(name)
+		for _, step := range []int{1, 7, 64, 128, len(data)} {
+			h := newHash()
+			for off := 0; off < len(data); off += step {
+				h.Write(data[off:min(off+step, len(data))])
+			}
+			if got := hex.EncodeToString(h.Sum(nil)); got != want {
+				t.Errorf("%s, step %d: got %s, want %s", name, step, got, want)
+			}
+		}
+	}
+}
+
+func TestBLAKE2_ByNameKeyErrors(t *testing.T) {
+	for name, keyLen := range map[string]int{"blake2b-256": 65, "blake2s-256": 33} {
+		_, err := NewBLAKE2ByName(name, make([]byte, keyLen))
+		if !errors.Is(err, errBlake2Param) {
+			t.Fatalf("%s with %d-byte key: expected errBlake2Param, got %v", name, keyLen, err)
+		}
+	}
+	if _, err := NewBLAKE2ByName("blake2s-256", make([]byte, 32)); err != nil {
+		t.Fatal(err)
+	}
+}
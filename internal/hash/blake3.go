@@ -0,0 +1,248 @@
+package hash
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// BLAKE3: дерево над чанками по 1 КиБ, функция сжатия — 7 раундов G из BLAKE2s.
+// Чанк i сжимается со счётчиком i независимо от остальных, поэтому поддеревья
+// можно считать параллельно (ParallelBLAKE3) и получить тот же дайджест.
+
+const (
+	blake3ChunkLen = 1024
+	blake3KeyLen   = 32
+
+	blake3ChunkStart        = 1 << 0
+	blake3ChunkEnd          = 1 << 1
+	blake3Parent            = 1 << 2
+	blake3Root              = 1 << 3
+	blake3KeyedHash         = 1 << 4
+	blake3DeriveKeyContext  = 1 << 5
+	blake3DeriveKeyMaterial = 1 << 6
+)
+
+var blake3MsgPermutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+// blake3Compress: IV — тот же, что у BLAKE2s и SHA-256.
+func blake3Compress(cv *[8]uint32, m [16]uint32, counter uint64, blockLen, flags uint32) [16]uint32 {
+	v := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		blake2sIV[0], blake2sIV[1], blake2sIV[2], blake2sIV[3],
+		uint32(counter), uint32(counter >> 32), blockLen, flags,
+	}
+	for r := 0; r < 7; r++ {
+		blake2sG(&v, 0, 4, 8, 12, m[0], m[1])
+		blake2sG(&v, 1, 5, 9, 13, m[2], m[3])
+		blake2sG(&v, 2, 6, 10, 14, m[4], m[5])
+		blake2sG(&v, 3, 7, 11, 15, m[6], m[7])
+		blake2sG(&v, 0, 5, 10, 15, m[8], m[9])
+		blake2sG(&v, 1, 6, 11, 12, m[10], m[11])
+		blake2sG(&v, 2, 7, 8, 13, m[12], m[13])
+		blake2sG(&v, 3, 4, 9, 14, m[14], m[15])
+		var p [16]uint32
+		for i, j := range blake3MsgPermutation {
+			p[i] = m[j]
+		}
+		m = p
+	}
+	for i := 0; i < 8; i++ {
+		v[i] ^= v[i+8]
+		v[i+8] ^= cv[i]
+	}
+	return v
+}
+
+func blake3Words(b []byte) [16]uint32 {
+	var m [16]uint32
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint32(b[4*i:])
+	}
+	return m
+}
+
+// blake3Output: последнее сжатие узла, отложенное до выбора — промежуточный CV или корень.
+type blake3Output struct {
+	cv       [8]uint32
+	block    [16]uint32
+	counter  uint64
+	blockLen uint32
+	flags    uint32
+}
+
+func (o *blake3Output) chainingValue() [8]uint32 {
+	v := blake3Compress(&o.cv, o.block, o.counter, o.blockLen, o.flags)
+	var cv [8]uint32
+	copy(cv[:], v[:8])
+	return cv
+}
+
+// rootBytes заполняет out выходом корня; счётчик блока делает выход XOF произвольной длины.
+func (o *blake3Output) rootBytes(out []byte) {
+	var buf [64]byte
+	for counter := uint64(0); len(out) > 0; counter++ {
+		v := blake3Compress(&o.cv, o.block, counter, o.blockLen, o.flags|blake3Root)
+		for i, w := range v {
+			binary.LittleEndian.PutUint32(buf[4*i:], w)
+		}
+		out = out[copy(out, buf[:]):]
+	}
+}
+
+func blake3ParentOutput(left, right [8]uint32, key *[8]uint32, flags uint32) blake3Output {
+	var m [16]uint32
+	copy(m[:8], left[:])
+	copy(m[8:], right[:])
+	return blake3Output{cv: *key, block: m, blockLen: 64, flags: flags | blake3Parent}
+}
+
+type blake3Chunk struct {
+	cv               [8]uint32
+	counter          uint64
+	block            [64]byte
+	blockLen         int
+	blocksCompressed int
+	flags            uint32
+}
+
+func newBlake3Chunk(key *[8]uint32, counter uint64, flags uint32) blake3Chunk {
+	return blake3Chunk{cv: *key, counter: counter, flags: flags}
+}
+
+func (c *blake3Chunk) len() int { return 64*c.blocksCompressed + c.blockLen }
+
+func (c *blake3Chunk) startFlag() uint32 {
+	if c.blocksCompressed == 0 {
+		return blake3ChunkStart
+	}
+	return 0
+}
+
+// write: полный блок сжимается только при поступлении следующих байт — последний блок
+// чанка уходит в output с флагом CHUNK_END.
+func (c *blake3Chunk) write(p []byte) []byte {
+	for len(p) > 0 && c.len() < blake3ChunkLen {
+		if c.blockLen == 64 {
+			v := blake3Compress(&c.cv, blake3Words(c.block[:]), c.counter, 64, c.flags|c.startFlag())
+			copy(c.cv[:], v[:8])
+			c.blocksCompressed++
+			c.block = [64]byte{}
+			c.blockLen = 0
+		}
+		k := copy(c.block[c.blockLen:], p)
+		c.blockLen += k
+		p = p[k:]
+	}
+	return p
+}
+
+func (c *blake3Chunk) output() blake3Output {
+	return blake3Output{
+		cv:       c.cv,
+		block:    blake3Words(c.block[:]),
+		counter:  c.counter,
+		blockLen: uint32(c.blockLen),
+		flags:    c.flags | c.startFlag() | blake3ChunkEnd,
+	}
+}
+
+// DigestBLAKE3: BLAKE3 как hash.Hash (32-байтный дайджест); Finalize даёт выход любой длины.
+type DigestBLAKE3 struct {
+	key      [8]uint32
+	flags    uint32
+	chunk    blake3Chunk
+	stack    [54][8]uint32 // 2^54 чанков — больше, чем допускает 64-битный счётчик байт
+	stackLen int
+}
+
+func newBLAKE3(key [8]uint32, flags uint32) *DigestBLAKE3 {
+	d := &DigestBLAKE3{key: key, flags: flags}
+	d.Reset()
+	return d
+}
+
+// NewBLAKE3 — обычное хеширование.
+func NewBLAKE3() *DigestBLAKE3 {
+	return newBLAKE3(blake2sIV, 0)
+}
+
+// NewBLAKE3Keyed — ключевой режим (MAC), ключ ровно 32 байта.
+func NewBLAKE3Keyed(key []byte) (*DigestBLAKE3, error) {
+	if len(key) != blake3KeyLen {
+		return nil, fmt.Errorf("blake3 key must be %d bytes, got %d", blake3KeyLen, len(key))
+	}
+	var k [8]uint32
+	for i := range k {
+		k[i] = binary.LittleEndian.Uint32(key[4*i:])
+	}
+	return newBLAKE3(k, blake3KeyedHash), nil
+}
+
+// NewBLAKE3DeriveKey — вывод ключа: context — жёстко заданная строка приложения,
+// ключевой материал подаётся через Write.
+func NewBLAKE3DeriveKey(context string) *DigestBLAKE3 {
+	h := newBLAKE3(blake2sIV, blake3DeriveKeyContext)
+	h.Write([]byte(context))
+	var contextKey [blake3KeyLen]byte
+	h.Finalize(contextKey[:])
+	var k [8]uint32
+	for i := range k {
+		k[i] = binary.LittleEndian.Uint32(contextKey[4*i:])
+	}
+	return newBLAKE3(k, blake3DeriveKeyMaterial)
+}
+
+func (d *DigestBLAKE3) Reset() {
+	d.chunk = newBlake3Chunk(&d.key, 0, d.flags)
+	d.stackLen = 0
+}
+
+// pushSubtree добавляет CV законченного поддерева из 1<<level чанков, после которого
+// всего обработано total чанков, и сливает равные по высоте поддеревья.
+func (d *DigestBLAKE3) pushSubtree(cv [8]uint32, total uint64, level uint) {
+	for total >>= level; total&1 == 0; total >>= 1 {
+		d.stackLen--
+		out := blake3ParentOutput(d.stack[d.stackLen], cv, &d.key, d.flags)
+		cv = out.chainingValue()
+	}
+	d.stack[d.stackLen] = cv
+	d.stackLen++
+}
+
+func (d *DigestBLAKE3) Write(p []byte) (int, error) {
+	nn := len(p)
+	for len(p) > 0 {
+		// чанк закрывается, только когда известно, что он не последний
+		if d.chunk.len() == blake3ChunkLen {
+			out := d.chunk.output()
+			total := d.chunk.counter + 1
+			d.pushSubtree(out.chainingValue(), total, 0)
+			d.chunk = newBlake3Chunk(&d.key, total, d.flags)
+		}
+		p = d.chunk.write(p)
+	}
+	return nn, nil
+}
+
+func (d *DigestBLAKE3) finalOutput() blake3Output {
+	out := d.chunk.output()
+	for i := d.stackLen - 1; i >= 0; i-- {
+		out = blake3ParentOutput(d.stack[i], out.chainingValue(), &d.key, d.flags)
+	}
+	return out
+}
+
+// Finalize заполняет out первыми len(out) байтами выхода; состояние не меняется.
+func (d *DigestBLAKE3) Finalize(out []byte) {
+	o := d.finalOutput()
+	o.rootBytes(out)
+}
+
+func (d *DigestBLAKE3) Sum(b []byte) []byte {
+	var digest [32]byte
+	d.Finalize(digest[:])
+	return append(b, digest[:]...)
+}
+
+func (d *DigestBLAKE3) Size() int      { return 32 }
+func (d *DigestBLAKE3) BlockSize() int { return 64 }
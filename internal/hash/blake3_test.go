@@ -0,0 +1,103 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Официальные векторы BLAKE3 (test_vectors.json): вход — байты i % 251, первые 32 байта выхода.
+const (
+	blake3TestKey     = "whats the Elvish word for friend"
+	blake3TestContext = "BLAKE3 2019-12-27 16:29:52 test vectors context"
+)
+
+func blake3TestInput(n int) []byte {
+	in := make([]byte, n)
+	for i := range in {
+		in[i] = byte(i % 251)
+	}
+	return in
+}
+
+var blake3Vectors = []struct {
+	inputLen                   int
+	hash, keyedHash, deriveKey string
+}{
+	{0, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262", "92b2b75604ed3c761f9d6f62392c8a9227ad0ea3f09573e783f1498a4ed60d26", "2cc39783c223154fea8dfb7c1b1660f2ac2dcbd1c1de8277b0b0dd39b7e50d7d"},
+	{1, "2d3adedff11b61f14c886e35afa036736dcd87a74d27b5c1510225d0f592e213", "6d7878dfff2f485635d39013278ae14f1454b8c0a3a2d34bc1ab38228a80c95b", "b3e2e340a117a499c6cf2398a19ee0d29cca2bb7404c73063382693bf66cb06c"},
+	{1023, "10108970eeda3eb932baac1428c7a2163b0e924c9a9e25b35bba72b28f70bd11", "c951ecdf03288d0fcc96ee3413563d8a6d3589547f2c2fb36d9786470f1b9d6e", "74a16c1c3d44368a86e1ca6df64be6a2f64cce8f09220787450722d85725dea5"},
+	{1024, "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7", "75c46f6f3d9eb4f55ecaaee480db732e6c2105546f1e675003687c31719c7ba4", "7356cd7720d5b66b6d0697eb3177d9f8d73a4a5c5e968896eb6a689684302706"},
+	{1025, "d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444", "357dc55de0c7e382c900fd6e320acc04146be01db6a8ce7210b7189bd664ea69", "effaa245f065fbf82ac186839a249707c3bddf6d3fdda22d1b95a3c970379bcb"},
+	{2048, "e776b6028c7cd22a4d0ba182a8bf62205d2ef576467e838ed6f2529b85fba24a", "879cf1fa2ea0e79126cb1063617a05b6ad9d0b696d0d757cf053439f60a99dd1", "7b2945cb4fef70885cc5d78a87bf6f6207dd901ff239201351ffac04e1088a23"},
+	{3073, "7124b49501012f81cc7f11ca069ec9226cecb8a2c850cfe644e327d22d3e1cd3", "68dede9bef00ba89e43f31a6825f4cf433389fedae75c04ee9f0cf16a427c95a", "72613c9ec9ff7e40f8f5c173784c532ad852e827dba2bf85b2ab4b76f7079081"},
+	{8193, "bab6c09cb8ce8cf459261398d2e7aef35700bf488116ceb94a36d0f5f1b7bc3b", "954a2a75420c8d6547e3ba5b98d963e6fa6491addc8c023189cc519821b4a1f5", "af1e0346e389b17c23200270a64aa4e1ead98c61695d917de7d5b00491c9b0f1"},
+	{31744, "62b6960e1a44bcc1eb1a611a8d6235b6b4b78f32e7abc4fb4c6cdcce94895c47", "efa53b389ab67c593dba624d898d0f7353ab99e4ac9d42302ee64cbf9939a419", "39772aef80e0ebe60596361e45b061e8f417429d529171b6764468c22928e28e"},
+}
+
+func TestBLAKE3_Vectors(t *testing.T) {
+	for _, v := range blake3Vectors {
+		in := blake3TestInput(v.inputLen)
+		keyed, err := NewBLAKE3Keyed([]byte(blake3TestKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashers := map[string]*DigestBLAKE3{
+			v.hash:      NewBLAKE3(),
+			v.keyedHash: keyed,
+			v.deriveKey: NewBLAKE3DeriveKey(blake3TestContext),
+		}
+		for want, h := range hashers {
+			// неровными кусками — чтобы пройти через границы блоков и чанков
+			for off := 0; off < len(in); off += 100 {
+				h.Write(in[off:min(off+100, len(in))])
+			}
+			if got := hex.EncodeToString(h.Sum(nil)); got != want {
+				t.Errorf("len %d: got %s, want %s", v.inputLen, got, want)
+			}
+		}
+	}
+}
+
+func TestBLAKE3_ExtendedOutput(t *testing.T) {
+	// полный 131-байтный выход для пустого входа: два блока корня со счётчиками 0 и 1, третий частично
+	want := "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262e00f03e7b69af26b7faaf09fcd333050338ddfe085b8cc869ca98b206c08243a26f5487789e8f660afe6c99ef9e0c52b92e7393024a80459cf91f476f9ffdbda7001c22e159b402631f277ca96f2defdf1078282314e763699a31c5363165421cce14d"
+	out := make([]byte, 131)
+	NewBLAKE3().Finalize(out)
+	if got := hex.EncodeToString(out); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestParallelBLAKE3_MatchesSerial(t *testing.T) {
+	// 4 МиБ: выход из набора векторов github.com/zeebo/blake3; остальные длины сверяем
+	// с последовательным Write — ровно по сегментам, с хвостом и меньше одного сегмента
+	const fourMiB = "4e94e6f582581a0f3855f3ce504b153e951e65036fe9e2f010b7e25473c54f98"
+	for _, n := range []int{0, 1000, ChunkSize, 3*ChunkSize + 5, 4 * ChunkSize} {
+		in := blake3TestInput(n)
+		serial := NewBLAKE3()
+		serial.Write(in)
+		want := hex.EncodeToString(serial.Sum(nil))
+		if n == 4*ChunkSize && want != fourMiB {
+			t.Fatalf("4 MiB serial: got %s, want %s", want, fourMiB)
+		}
+
+		for _, workers := range []int{1, 3, 8} {
+			h := NewBLAKE3()
+			if err := ParallelBLAKE3(h, bytes.NewReader(in), workers); err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(h.Sum(nil)); got != want {
+				t.Fatalf("len %d, %d workers: got %s, want %s", n, workers, got, want)
+			}
+		}
+	}
+
+	keyed, _ := NewBLAKE3Keyed([]byte(blake3TestKey))
+	if err := ParallelBLAKE3(keyed, bytes.NewReader(blake3TestInput(31744)), 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(keyed.Sum(nil)); got != blake3Vectors[len(blake3Vectors)-1].keyedHash {
+		t.Fatalf("keyed: got %s", got)
+	}
+}
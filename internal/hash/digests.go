@@ -0,0 +1,119 @@
+package hash
+
+import (
+	"fmt"
+	stdhash "hash"
+	"strings"
+)
+
+// Digests: собственные хеш-функции по имени --algorithm (dgst, hmac, derive).
+var digests = map[string]func() stdhash.Hash{
+	"sha224":     func() stdhash.Hash { return NewSHA224() },
+	"sha256":     func() stdhash.Hash { return NewSHA256() },
+	"sha384":     func() stdhash.Hash { return NewSHA384() },
+	"sha512":     func() stdhash.Hash { return NewSHA512() },
+	"sha512-224": func() stdhash.Hash { return NewSHA512_224() },
+	"sha512-256": func() stdhash.Hash { return NewSHA512_256() },
+	"sha3-224":   func() stdhash.Hash { return NewSHA3_224() },
+	"sha3-256":   func() stdhash.Hash { return NewSHA3_256() },
+	"sha3-384":   func() stdhash.Hash { return NewSHA3_384() },
+	"sha3-512":   func() stdhash.Hash { return NewSHA3_512() },
+	"blake3":     func() stdhash.Hash { return NewBLAKE3() },
+}
+
+// blake2Sizes: варианты BLAKE2 для dgst — имя и длина дайджеста в байтах.
+var blake2Sizes = map[string]int{
+	"blake2b-160": 20, "blake2b-256": 32, "blake2b-384": 48, "blake2b-512": 64,
+	"blake2s-128": 16, "blake2s-160": 20, "blake2s-224": 28, "blake2s-256": 32,
+}
+
+func init() {
+	for name := range blake2Sizes {
+		name := name
+		digests[name] = func() stdhash.Hash {
+			h, _ := NewBLAKE2ByName(name, nil) // имя и размер заведомо допустимы
+			return h
+		}
+	}
+}
+
+// DigestNames — имена в порядке для справки и сообщений об ошибках.
+var DigestNames = []string{
+	"sha224", "sha256", "sha384", "sha512", "sha512-224", "sha512-256",
+	"sha3-224", "sha3-256", "sha3-384", "sha3-512",
+	"blake2b-160", "blake2b-256", "blake2b-384", "blake2b-512",
+	"blake2s-128", "blake2s-160", "blake2s-224", "blake2s-256",
+	"blake3",
+}
+
+// Digest возвращает конструктор хеш-функции name.
+func Digest(name string) (func() stdhash.Hash, error) {
+	f, ok := digests[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm %q (%s)", name, strings.Join(DigestNames, ", "))
+	}
+	return f, nil
+}
+
+// IsBLAKE2 сообщает, является ли name вариантом BLAKE2 (у них собственный режим с ключом).
+func IsBLAKE2(name string) bool {
+	_, ok := blake2Sizes[name]
+	return ok
+}
+
+// NewBLAKE2ByName создаёт BLAKE2 name; непустой key даёт ключевой BLAKE2 (MAC вместо HMAC).
+func NewBLAKE2ByName(name string, key []byte) (stdhash.Hash, error) {
+	size, ok := blake2Sizes[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported BLAKE2 variant %q", name)
+	}
+	p := Blake2Params{Size: size, Key: key}
+	// ошибки возвращаются явно: типизированный nil в интерфейсе не равен nil
+	if strings.HasPrefix(name, "blake2b") {
+		d, err := NewBLAKE2b(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return d, nil
+	}
+	d, err := NewBLAKE2s(p)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return d, nil
+}
+
+// xofDefaultLengths: длина выхода XOF по умолчанию (байты) — удвоенный уровень стойкости.
+var xofDefaultLengths = map[string]int{"shake128": 32, "shake256": 64, "cshake128": 32, "cshake256": 64}
+
+// XOFNames — функции с выходом произвольной длины для dgst --length.
+var XOFNames = []string{"shake128", "shake256", "cshake128", "cshake256"}
+
+// IsXOF сообщает, является ли name функцией с выходом произвольной длины.
+func IsXOF(name string) bool {
+	_, ok := xofDefaultLengths[name]
+	return ok
+}
+
+// NewXOFByName создаёт XOF и возвращает его длину выхода по умолчанию. customization —
+// строка S для cSHAKE (имя функции N пустое); у SHAKE её быть не может.
+func NewXOFByName(name string, customization []byte) (*XOF, int, error) {
+	n, ok := xofDefaultLengths[name]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported XOF %q (%s)", name, strings.Join(XOFNames, ", "))
+	}
+	switch name {
+	case "shake128", "shake256":
+		if len(customization) > 0 {
+			return nil, 0, fmt.Errorf("%s takes no customization string; use c%s", name, name)
+		}
+		if name == "shake128" {
+			return NewSHAKE128(), n, nil
+		}
+		return NewSHAKE256(), n, nil
+	case "cshake128":
+		return NewCSHAKE128(nil, customization), n, nil
+	default:
+		return NewCSHAKE256(nil, customization), n, nil
+	}
+}
@@ -0,0 +1,191 @@
+package hash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Дерево Меркла SHA-256 над чанками файла по RFC 6962, 2.1:
+//
+//	лист  = SHA-256(0x00 || чанк)
+//	узел  = SHA-256(0x01 || левый || правый)
+//	корень над n > 1 листьями: левое поддерево — первые k листьев, где k — наибольшая
+//	степень двойки меньше n; пустой файл — SHA-256("").
+//
+// Префиксы разделяют листья и узлы: хеш листа нельзя выдать за внутренний узел. Дайджест
+// записывается вместе с размером чанка — "sha256-tree-1M:<hex>" — и от числа CPU не зависит.
+
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+
+	// TreeAlgorithm — имя режима в dgst и префикс строки дайджеста.
+	TreeAlgorithm = "sha256-tree"
+
+	// чанк читается в память целиком, по одному на воркер, поэтому предел невелик
+	minTreeChunkSize = 1 << 10
+	maxTreeChunkSize = 64 << 20
+)
+
+// MerkleLeafHash — хеш листа над данными чанка.
+func MerkleLeafHash(chunk []byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte{merkleLeafPrefix})
+	h.Write(chunk)
+	var sum [32]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// MerkleNodeHash — хеш внутреннего узла над хешами детей.
+func MerkleNodeHash(left, right [32]byte) [32]byte {
+	var buf [1 + 2*32]byte
+	buf[0] = merkleNodePrefix
+	copy(buf[1:], left[:])
+	copy(buf[33:], right[:])
+	return sha256.Sum256(buf[:])
+}
+
+// merkleSplit: наибольшая степень двойки, строго меньшая n (n > 1).
+func merkleSplit(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// MerkleRoot вычисляет корень над хешами листьев.
+func MerkleRoot(leaves [][32]byte) [32]byte {
+	switch len(leaves) {
+	case 0:
+		return sha256.Sum256(nil)
+	case 1:
+		return leaves[0]
+	}
+	k := merkleSplit(len(leaves))
+	return MerkleNodeHash(MerkleRoot(leaves[:k]), MerkleRoot(leaves[k:]))
+}
+
+// TreeLeaves читает r чанками по chunkSize и хеширует листья в workers горутинах
+// (0 — по числу CPU). Пустой вход даёт пустой список.
+func TreeLeaves(r io.Reader, chunkSize, workers int) ([][32]byte, error) {
+	if err := checkTreeChunkSize(chunkSize); err != nil {
+		return nil, err
+	}
+	leaves, last, err := parallelChunks(r, chunkSize, workers, func(_ int, data []byte) [32]byte {
+		return MerkleLeafHash(data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(last) > 0 {
+		leaves = append(leaves, MerkleLeafHash(last))
+	}
+	return leaves, nil
+}
+
+// TreeSHA256 возвращает корень дерева над r.
+func TreeSHA256(r io.Reader, chunkSize, workers int) ([]byte, error) {
+	leaves, err := TreeLeaves(r, chunkSize, workers)
+	if err != nil {
+		return nil, err
+	}
+	root := MerkleRoot(leaves)
+	return root[:], nil
+}
+
+// TreeDigest возвращает строку дайджеста FormatTreeDigest для r.
+func TreeDigest(r io.Reader, chunkSize, workers int) (string, error) {
+	leaves, err := TreeLeaves(r, chunkSize, workers)
+	if err != nil {
+		return "", err
+	}
+	root := MerkleRoot(leaves)
+	return FormatTreeDigest(chunkSize, len(leaves), root[:]), nil
+}
+
+func checkTreeChunkSize(size int) error {
+	if size < minTreeChunkSize || size > maxTreeChunkSize || size&(size-1) != 0 {
+		return fmt.Errorf("tree chunk size must be a power of two from 1K to 64M, got %d", size)
+	}
+	return nil
+}
+
+// FormatChunkSize записывает размер чанка как 64K, 1M.
+func FormatChunkSize(size int) string {
+	switch {
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return strconv.Itoa(size>>20) + "M"
+	default:
+		return strconv.Itoa(size>>10) + "K"
+	}
+}
+
+// ParseChunkSize разбирает запись FormatChunkSize; принимается только каноническая форма,
+// чтобы у одного дерева была ровно одна строка дайджеста.
+func ParseChunkSize(s string) (int, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid chunk size %q (power of two from 1K to 64M, e.g. 64K, 1M)", s)
+	}
+	shift := map[byte]uint{'K': 10, 'M': 20}[s[len(s)-1]]
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if shift == 0 || err != nil || n <= 0 || n > maxTreeChunkSize>>shift {
+		return 0, fmt.Errorf("invalid chunk size %q (power of two from 1K to 64M, e.g. 64K, 1M)", s)
+	}
+	size := n << shift
+	if err := checkTreeChunkSize(size); err != nil {
+		return 0, err
+	}
+	if FormatChunkSize(size) != s {
+		return 0, fmt.Errorf("chunk size %q must be written as %s", s, FormatChunkSize(size))
+	}
+	return size, nil
+}
+
+// FormatTreeDigest: "sha256-tree-<размер чанка>:<число чанков>:<hex корня>".
+// Число чанков входит в дайджест, потому что корень RFC 6962 сам его не фиксирует:
+// путь аудита листа может пройти проверку и с другим размером дерева и номером листа.
+func FormatTreeDigest(chunkSize, chunks int, root []byte) string {
+	return fmt.Sprintf("%s-%s:%d:%s", TreeAlgorithm, FormatChunkSize(chunkSize), chunks, hex.EncodeToString(root))
+}
+
+// ParseTreeDigest разбирает строку FormatTreeDigest.
+func ParseTreeDigest(s string) (chunkSize, chunks int, root []byte, err error) {
+	fields := strings.Split(s, ":")
+	var sizeLabel string
+	found := false
+	if len(fields) == 3 {
+		sizeLabel, found = strings.CutPrefix(fields[0], TreeAlgorithm+"-")
+	}
+	if !found {
+		return 0, 0, nil, fmt.Errorf("tree digest must look like %s-1M:<chunks>:<hex>", TreeAlgorithm)
+	}
+	if chunkSize, err = ParseChunkSize(sizeLabel); err != nil {
+		return 0, 0, nil, err
+	}
+	// только каноническая десятичная запись, как и у размера чанка
+	if chunks, err = strconv.Atoi(fields[1]); err != nil || chunks < 0 || strconv.Itoa(chunks) != fields[1] {
+		return 0, 0, nil, fmt.Errorf("tree digest chunk count %q must be a non-negative decimal number", fields[1])
+	}
+	if root, err = hex.DecodeString(fields[2]); err != nil || len(root) != sha256.Size {
+		return 0, 0, nil, fmt.Errorf("tree digest root must be %d bytes in hex", sha256.Size)
+	}
+	return chunkSize, chunks, root, nil
+}
+
+// VerifyTreeSHA256 пересчитывает дерево над r по параметрам из строки digest.
+func VerifyTreeSHA256(r io.Reader, digest string, workers int) (bool, error) {
+	chunkSize, chunks, want, err := ParseTreeDigest(digest)
+	if err != nil {
+		return false, err
+	}
+	leaves, err := TreeLeaves(r, chunkSize, workers)
+	if err != nil {
+		return false, err
+	}
+	got := MerkleRoot(leaves)
+	return len(leaves) == chunks && bytes.Equal(got[:], want), nil
+}
@@ -0,0 +1,122 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Листья и корни деревьев размером 1..8 из тестов certificate-transparency (RFC 6962).
+var rfc6962Leaves = [][]byte{
+	{}, {0x00}, {0x10}, {0x20, 0x21}, {0x30, 0x31}, {0x40, 0x41, 0x42, 0x43},
+	{0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57},
+	{0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69, 0x6a, 0x6b, 0x6c, 0x6d, 0x6e, 0x6f},
+}
+
+var rfc6962Roots = []string{
+	"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+	"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+	"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+	"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+	"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+	"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+	"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+}
+
+func TestMerkleRoot_RFC6962(t *testing.T) {
+	var leaves [][32]byte
+	for i, data := range rfc6962Leaves {
+		leaves = append(leaves, MerkleLeafHash(data))
+		root := MerkleRoot(leaves)
+		if got := hex.EncodeToString(root[:]); got != rfc6962Roots[i] {
+			t.Errorf("%d leaves: got %s, want %s", i+1, got, rfc6962Roots[i])
+		}
+	}
+	empty := MerkleRoot(nil)
+	if got := hex.EncodeToString(empty[:]); got != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("empty tree: got %s", got)
+	}
+}
+
+func TestTreeSHA256_IndependentOfWorkers(t *testing.T) {
+	// корни посчитаны независимой реализацией RFC 6962 на Python (hashlib)
+	vectors := []struct {
+		inputLen, chunkSize, chunks int
+		want                        string
+	}{
+		{5*1024 + 17, 1 << 10, 6, "5a05d72f36d32bc59d4229b3fbddb08b39cd6fe9bbc4f6ab4cecf9ad723d9c49"},
+		{3 << 20, 1 << 20, 3, "062d9057692524803edcdf17d4b5465325bbb35d99cc9c65907aa4f0dfa4449c"},
+	}
+	for _, v := range vectors {
+		in := blake3TestInput(v.inputLen)
+		for _, workers := range []int{1, 2, 7} {
+			root, err := TreeSHA256(bytes.NewReader(in), v.chunkSize, workers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(root); got != v.want {
+				t.Fatalf("len %d, %d workers: got %s, want %s", v.inputLen, workers, got, v.want)
+			}
+		}
+
+		digest := FormatTreeDigest(v.chunkSize, v.chunks, mustDecodeHex(t, v.want))
+		if got, err := TreeDigest(bytes.NewReader(in), v.chunkSize, 0); err != nil || got != digest {
+			t.Fatalf("TreeDigest: got %s, %v; want %s", got, err, digest)
+		}
+		ok, err := VerifyTreeSHA256(bytes.NewReader(in), digest, 0)
+		if err != nil || !ok {
+			t.Fatalf("verify %s: %v, %v", digest, ok, err)
+		}
+		wrongCount := FormatTreeDigest(v.chunkSize, v.chunks+1, mustDecodeHex(t, v.want))
+		if ok, _ := VerifyTreeSHA256(bytes.NewReader(in), wrongCount, 0); ok {
+			t.Fatalf("verify %s accepted a wrong chunk count", wrongCount)
+		}
+		in[len(in)-1] ^= 1
+		if ok, _ := VerifyTreeSHA256(bytes.NewReader(in), digest, 0); ok {
+			t.Fatalf("verify %s accepted modified input", digest)
+		}
+	}
+}
+
+func TestTreeDigest_Format(t *testing.T) {
+	root := make([]byte, 32)
+	digest := FormatTreeDigest(1<<20, 58, root)
+	if digest != "sha256-tree-1M:58:"+hex.EncodeToString(root) {
+		t.Fatalf("got %s", digest)
+	}
+	if size, chunks, _, err := ParseTreeDigest(digest); err != nil || size != 1<<20 || chunks != 58 {
+		t.Fatalf("parse %s: %d, %d, %v", digest, size, chunks, err)
+	}
+	for _, s := range []string{"64K", "1M", "4M", "64M"} {
+		size, err := ParseChunkSize(s)
+		if err != nil || FormatChunkSize(size) != s {
+			t.Fatalf("%s: got %d, %v", s, size, err)
+		}
+	}
+	// неканоническая запись, не степень двойки, вне диапазона
+	for _, s := range []string{"1024K", "3M", "512", "128M", "1G", "0K", "M"} {
+		if _, err := ParseChunkSize(s); err == nil {
+			t.Fatalf("%s: expected error", s)
+		}
+	}
+	for _, s := range []string{
+		"sha256-1M:58:" + hex.EncodeToString(root),   // не та метка
+		"sha256-tree-1M:" + hex.EncodeToString(root), // без числа чанков
+		"sha256-tree-1M:058:" + hex.EncodeToString(root),
+		"sha256-tree-1M:-1:" + hex.EncodeToString(root),
+	} {
+		if _, _, _, err := ParseTreeDigest(s); err == nil {
+			t.Fatalf("%s: expected error", s)
+		}
+	}
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
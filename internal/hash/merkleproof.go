@@ -0,0 +1,147 @@
+package hash
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Доказательство включения чанка в дерево sha256-tree (RFC 6962, 2.1.1): соседние узлы
+// на пути от листа к корню. Вместе с номером чанка и числом чанков этого достаточно, чтобы
+// проверить один скачанный чанк по корневому дайджесту, не имея остального файла.
+// Номер и число чанков в файле доказательства ничем не защищены: число чанков сверяется
+// с дайджестом, а номер — с тем, который запросил проверяющий.
+
+const proofHeader = "sha256-tree-proof"
+
+// InclusionProof: путь аудита для чанка Index в дереве из TreeSize чанков.
+type InclusionProof struct {
+	Index    int
+	TreeSize int
+	Path     [][32]byte // от листа к корню
+}
+
+// MerkleInclusionProof строит доказательство для листа index.
+func MerkleInclusionProof(leaves [][32]byte, index int) (*InclusionProof, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("chunk %d out of range: the file has %d chunks", index, len(leaves))
+	}
+	return &InclusionProof{Index: index, TreeSize: len(leaves), Path: merklePath(index, leaves)}, nil
+}
+
+// merklePath — PATH(m, D[n]) из RFC 6962: сначала путь внутри поддерева с листом m,
+// затем корень соседнего поддерева.
+func merklePath(m int, leaves [][32]byte) [][32]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := merkleSplit(len(leaves))
+	if m < k {
+		return append(merklePath(m, leaves[:k]), MerkleRoot(leaves[k:]))
+	}
+	return append(merklePath(m-k, leaves[k:]), MerkleRoot(leaves[:k]))
+}
+
+// Verify проверяет, что лист leaf входит в дерево с корнем root (RFC 9162, 2.1.3.2).
+func (p *InclusionProof) Verify(leaf, root [32]byte) bool {
+	if p.Index < 0 || p.Index >= p.TreeSize {
+		return false
+	}
+	fn, sn := p.Index, p.TreeSize-1
+	r := leaf
+	for _, sibling := range p.Path {
+		if sn == 0 {
+			return false // путь длиннее дерева
+		}
+		if fn&1 == 1 || fn == sn {
+			r = MerkleNodeHash(sibling, r)
+			// правый край дерева: подняться до уровня, где у узла есть левый сосед
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = MerkleNodeHash(r, sibling)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && r == root
+}
+
+// String: текстовая форма доказательства —
+//
+//	sha256-tree-proof
+//	chunk <номер> of <число чанков>
+//	<hex соседа>   (по строке на уровень, от листа к корню)
+func (p *InclusionProof) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\nchunk %d of %d\n", proofHeader, p.Index, p.TreeSize)
+	for _, h := range p.Path {
+		b.WriteString(hex.EncodeToString(h[:]))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ParseInclusionProof разбирает вывод String; пустые строки и строки с # пропускаются.
+func ParseInclusionProof(data []byte) (*InclusionProof, error) {
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) < 2 || lines[0] != proofHeader {
+		return nil, errors.New("not a sha256-tree inclusion proof")
+	}
+
+	p := &InclusionProof{}
+	fields := strings.Fields(lines[1])
+	var err1, err2 error
+	if len(fields) == 4 && fields[0] == "chunk" && fields[2] == "of" {
+		p.Index, err1 = strconv.Atoi(fields[1])
+		p.TreeSize, err2 = strconv.Atoi(fields[3])
+	}
+	if len(fields) != 4 || err1 != nil || err2 != nil || p.Index < 0 || p.Index >= p.TreeSize {
+		return nil, fmt.Errorf("proof: invalid line %q (expected 'chunk <n> of <total>')", lines[1])
+	}
+	for _, line := range lines[2:] {
+		h, err := hex.DecodeString(line)
+		if err != nil || len(h) != 32 {
+			return nil, fmt.Errorf("proof: invalid path hash %q", line)
+		}
+		p.Path = append(p.Path, [32]byte(h))
+	}
+	return p, nil
+}
+
+// VerifyChunk проверяет, что chunk — чанк номер index файла с дайджестом
+// "sha256-tree-1M:<chunks>:<hex>". Все чанки, кроме последнего, обязаны иметь ровно
+// размер из дайджеста.
+func VerifyChunk(chunk []byte, index int, proof *InclusionProof, digest string) (bool, error) {
+	chunkSize, chunks, root, err := ParseTreeDigest(digest)
+	if err != nil {
+		return false, err
+	}
+	if proof.Index != index {
+		return false, fmt.Errorf("proof is for chunk %d, not chunk %d", proof.Index, index)
+	}
+	if proof.TreeSize != chunks {
+		return false, fmt.Errorf("proof is for a tree of %d chunks, the digest has %d", proof.TreeSize, chunks)
+	}
+	last := proof.Index == proof.TreeSize-1
+	if len(chunk) > chunkSize || (!last && len(chunk) != chunkSize) || len(chunk) == 0 {
+		return false, nil
+	}
+	return proof.Verify(MerkleLeafHash(chunk), [32]byte(root)), nil
+}
@@ -0,0 +1,134 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestInclusionProof_RFC6962(t *testing.T) {
+	var leaves [][32]byte
+	for _, data := range rfc6962Leaves {
+		leaves = append(leaves, MerkleLeafHash(data))
+	}
+
+	// пути из тестов certificate-transparency
+	vectors := []struct {
+		index, size int
+		path        []string
+	}{
+		{0, 8, []string{
+			"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+		}},
+		{5, 8, []string{
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+			"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+			"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		}},
+		{2, 3, []string{"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125"}},
+		{4, 5, []string{"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7"}},
+	}
+	for _, v := range vectors {
+		p, err := MerkleInclusionProof(leaves[:v.size], v.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Path) != len(v.path) {
+			t.Fatalf("%d/%d: path length %d, want %d", v.index, v.size, len(p.Path), len(v.path))
+		}
+		for i, h := range p.Path {
+			if got := hex.EncodeToString(h[:]); got != v.path[i] {
+				t.Errorf("%d/%d, level %d: got %s, want %s", v.index, v.size, i, got, v.path[i])
+			}
+		}
+	}
+
+	// каждый лист каждого дерева проверяется, чужой лист и чужой номер — нет
+	for size := 1; size <= len(leaves); size++ {
+		root := MerkleRoot(leaves[:size])
+		for index := 0; index < size; index++ {
+			p, _ := MerkleInclusionProof(leaves[:size], index)
+			if !p.Verify(leaves[index], root) {
+				t.Fatalf("%d/%d: valid proof rejected", index, size)
+			}
+			if p.Verify(leaves[(index+1)%len(leaves)], root) {
+				t.Fatalf("%d/%d: proof accepted a different leaf", index, size)
+			}
+			if size > 1 {
+				moved := *p
+				moved.Index = (index + 1) % size
+				if moved.Verify(leaves[index], root) {
+					t.Fatalf("%d/%d: proof accepted at index %d", index, size, moved.Index)
+				}
+			}
+		}
+	}
+}
+
+func TestVerifyChunk(t *testing.T) {
+	const chunkSize = 1 << 10
+	in := blake3TestInput(5*chunkSize + 17)
+	leaves, err := TreeLeaves(bytes.NewReader(in), chunkSize, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := MerkleRoot(leaves)
+	digest := FormatTreeDigest(chunkSize, len(leaves), root[:])
+
+	for index := range leaves {
+		p, _ := MerkleInclusionProof(leaves, index)
+		parsed, err := ParseInclusionProof([]byte("# comment\n" + p.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunk := in[index*chunkSize : min((index+1)*chunkSize, len(in))]
+		if ok, err := VerifyChunk(chunk, index, parsed, digest); err != nil || !ok {
+			t.Fatalf("chunk %d rejected: %v", index, err)
+		}
+		// укороченный чанк: для последнего — другой лист, для прочих — неверная длина
+		if ok, _ := VerifyChunk(chunk[:len(chunk)-1], index, parsed, digest); ok {
+			t.Fatalf("chunk %d: truncated chunk accepted", index)
+		}
+	}
+
+	// чанк не тот, что запрошен
+	p, _ := MerkleInclusionProof(leaves, 2)
+	if ok, err := VerifyChunk(in[2*chunkSize:3*chunkSize], 3, p, digest); ok || err == nil {
+		t.Fatal("proof for chunk 2 accepted as chunk 3")
+	}
+
+	if _, err := ParseInclusionProof([]byte("sha256-tree-proof\nchunk 6 of 6\n")); err == nil {
+		t.Fatal("expected error for index out of range")
+	}
+}
+
+// Путь RFC 6962 сам не фиксирует размер дерева: доказательство с подменённой строкой
+// "chunk N of M" проходит Verify, поэтому VerifyChunk сверяет число чанков с дайджестом.
+func TestVerifyChunk_RelabeledProof(t *testing.T) {
+	const chunkSize = 1 << 10
+	cases := []struct {
+		chunks, index         int // настоящие
+		fakeIndex, fakeChunks int // в подменённом доказательстве
+	}{
+		{4, 0, 0, 3}, // лист 0 дерева из 4 чанков как "chunk 0 of 3"
+		{5, 4, 1, 2}, // последний лист дерева из 5 чанков как "chunk 1 of 2"
+	}
+	for _, c := range cases {
+		in := blake3TestInput(c.chunks * chunkSize)
+		leaves, _ := TreeLeaves(bytes.NewReader(in), chunkSize, 1)
+		root := MerkleRoot(leaves)
+		digest := FormatTreeDigest(chunkSize, len(leaves), root[:])
+		chunk := in[c.index*chunkSize : (c.index+1)*chunkSize]
+
+		p, _ := MerkleInclusionProof(leaves, c.index)
+		forged := &InclusionProof{Index: c.fakeIndex, TreeSize: c.fakeChunks, Path: p.Path}
+		if !forged.Verify(leaves[c.index], root) {
+			t.Fatalf("%+v: relabeled path no longer verifies; the test lost its point", c)
+		}
+		if ok, err := VerifyChunk(chunk, c.fakeIndex, forged, digest); ok || err == nil {
+			t.Fatalf("%+v: relabeled proof accepted", c)
+		}
+	}
+}
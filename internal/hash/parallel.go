@@ -2,8 +2,9 @@ package hash
 
 import (
 	"crypto/sha256"
-	"fmt"
+	"errors"
 	"io"
+	"math/bits"
 	"runtime"
 	"sync"
 )
@@ -15,19 +16,27 @@ type chunkJob struct {
 	data  []byte
 }
 
-type chunkResult struct {
+type chunkResult[T any] struct {
 	index int
-	sum   [32]byte
+	sum   T
 }
 
-func ParallelSHA256(r io.Reader) ([]byte, error) {
-	workers := runtime.NumCPU()
+// parallelChunks читает r кусками по chunkSize и считает fn над всеми кусками, кроме
+// последнего, в workers горутинах. Последний кусок (возможно, неполный; пустой — только
+// для пустого входа) возвращается необработанным: корень дерева обычно считается иначе.
+// Буферы переиспользуются, поэтому fn не должна сохранять data; в памяти одновременно
+// не больше workers+2 кусков (по одному у воркеров, ожидающий и читаемый).
+func parallelChunks[T any](r io.Reader, chunkSize, workers int, fn func(index int, data []byte) T) ([]T, []byte, error) {
 	if workers < 1 {
-		workers = 1
+		workers = runtime.NumCPU()
 	}
 
-	jobs := make(chan chunkJob, workers*2)
-	results := make(chan chunkResult, workers*2)
+	jobs := make(chan chunkJob)
+	results := make(chan chunkResult[T], workers*2)
+	free := make(chan []byte, workers+2) // nil — буфер ещё не выделен
+	for i := 0; i < workers+2; i++ {
+		free <- nil
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(workers)
@@ -37,10 +46,9 @@ func ParallelSHA256(r io.Reader) ([]byte, error) {
 		go func() {
 			defer wg.Done()
 			for job := range jobs {
-				results <- chunkResult{
-					index: job.index,
-					sum:   sha256.Sum256(job.data),
-				}
+				sum := fn(job.index, job.data)
+				free <- job.data
+				results <- chunkResult[T]{index: job.index, sum: sum}
 			}
 		}()
 	}
@@ -51,57 +59,108 @@ func ParallelSHA256(r io.Reader) ([]byte, error) {
 		close(results)
 	}()
 
-	// Producer: read file and push jobs
+	// Collector: результаты собираются параллельно с чтением, иначе на больших файлах
+	// заполненный канал results остановил бы воркеров, а за ними и чтение.
+	var sums []T
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for res := range results {
+			for len(sums) <= res.index {
+				var zero T
+				sums = append(sums, zero)
+			}
+			sums[res.index] = res.sum
+		}
+	}()
+
+	// Producer: кусок отправляется воркерам, когда прочитан следующий
+	var pending []byte
 	chunks := 0
+	var readErr error
 	for {
-		buf := make([]byte, ChunkSize)
+		buf := <-free
+		if buf == nil {
+			buf = make([]byte, chunkSize)
+		}
 		n, err := io.ReadFull(r, buf)
 		if n > 0 {
-			data := make([]byte, n)
-			copy(data, buf[:n])
-
-			jobs <- chunkJob{index: chunks, data: data}
-			chunks++
-		}
-
-		if err == io.EOF {
-			break
+			if pending != nil {
+				jobs <- chunkJob{index: chunks, data: pending}
+				chunks++
+			}
+			pending = buf[:n]
 		}
-		if err == io.ErrUnexpectedEOF {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			break
 		}
 		if err != nil {
-			close(jobs)
-			return nil, err
+			readErr = err
+			break
 		}
 	}
 	close(jobs)
+	<-collected
 
-	if chunks == 0 {
-		// Hash of empty input (совместимо с идеей “SHA256(concat(chunk_hashes))”)
-		h := sha256.New()
-		return h.Sum(nil), nil
+	if readErr != nil {
+		return nil, nil, readErr
 	}
+	if len(sums) != chunks {
+		return nil, nil, errors.New("missing chunk results")
+	}
+	return sums, pending, nil
+}
 
-	// Collect results (order matters)
-	hashes := make([][32]byte, chunks)
-	got := 0
-	for res := range results {
-		if res.index < 0 || res.index >= chunks {
-			return nil, fmt.Errorf("invalid chunk index: %d", res.index)
-		}
-		hashes[res.index] = res.sum
-		got++
+// ParallelSHA256: SHA-256 от конкатенации хешей чанков по ChunkSize, которые считаются
+// в workers горутинах (0 — по числу CPU).
+func ParallelSHA256(r io.Reader, workers int) ([]byte, error) {
+	hashes, last, err := parallelChunks(r, ChunkSize, workers, func(_ int, data []byte) [32]byte {
+		return sha256.Sum256(data)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if got != chunks {
-		return nil, fmt.Errorf("missing results: got %d, want %d", got, chunks)
+	if len(last) == 0 {
+		// Hash of empty input (совместимо с идеей “SHA256(concat(chunk_hashes))”)
+		h := sha256.New()
+		return h.Sum(nil), nil
 	}
+	hashes = append(hashes, sha256.Sum256(last))
 
 	// Final = SHA256( hash(chunk1) || hash(chunk2) || ... )
 	final := sha256.New()
-	for i := 0; i < chunks; i++ {
+	for i := range hashes {
 		final.Write(hashes[i][:])
 	}
 	return final.Sum(nil), nil
 }
+
+// ParallelBLAKE3 подаёт r в пустой h, считая поддеревья по ChunkSize в workers горутинах
+// (0 — по числу CPU). Результат h.Sum/h.Finalize тот же, что после последовательного Write.
+func ParallelBLAKE3(h *DigestBLAKE3, r io.Reader, workers int) error {
+	if h.stackLen != 0 || h.chunk.counter != 0 || h.chunk.len() != 0 {
+		return errors.New("ParallelBLAKE3 requires a fresh hasher")
+	}
+	const chunksPerSegment = ChunkSize / blake3ChunkLen
+	level := uint(bits.TrailingZeros(chunksPerSegment))
+
+	cvs, last, err := parallelChunks(r, ChunkSize, workers, func(index int, data []byte) [8]uint32 {
+		sub := DigestBLAKE3{key: h.key, flags: h.flags}
+		sub.chunk = newBlake3Chunk(&h.key, uint64(index)*chunksPerSegment, h.flags)
+		sub.Write(data)
+		out := sub.finalOutput()
+		return out.chainingValue()
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, cv := range cvs {
+		h.pushSubtree(cv, uint64(i+1)*chunksPerSegment, level)
+	}
+	// последний сегмент содержит корень (или путь к нему) и считается последовательно
+	h.chunk = newBlake3Chunk(&h.key, uint64(len(cvs))*chunksPerSegment, h.flags)
+	h.Write(last)
+	return nil
+}
@@ -18,7 +18,7 @@ func BenchmarkParallelSHA256(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		reader.Seek(0, io.SeekStart)
-		ParallelSHA256(reader)
+		ParallelSHA256(reader, 0)
 	}
 }
 
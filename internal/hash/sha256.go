@@ -17,12 +17,13 @@ var k = [64]uint32{
 	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
 }
 
-// DigestSHA256 struct
+// DigestSHA256 struct; с is224 — SHA-224 (другие начальные значения, дайджест усечён до 28 байт).
 type DigestSHA256 struct {
-	h   [8]uint32
-	x   [64]byte
-	nx  int
-	len uint64
+	h     [8]uint32
+	x     [64]byte
+	nx    int
+	len   uint64
+	is224 bool
 }
 
 func NewSHA256() *DigestSHA256 {
@@ -31,10 +32,24 @@ func NewSHA256() *DigestSHA256 {
 	return d
 }
 
+// NewSHA224: SHA-224 (FIPS 180-4, 5.3.2).
+func NewSHA224() *DigestSHA256 {
+	d := &DigestSHA256{is224: true}
+	d.Reset()
+	return d
+}
+
 func (d *DigestSHA256) Reset() {
-	d.h = [8]uint32{
-		0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
-		0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+	if d.is224 {
+		d.h = [8]uint32{
+			0xc1059ed8, 0x367cd507, 0x3070dd17, 0xf70e5939,
+			0xffc00b31, 0x68581511, 0x64f98fa7, 0xbefa4fa4,
+		}
+	} else {
+		d.h = [8]uint32{
+			0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
+			0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+		}
 	}
 	d.nx = 0
 	d.len = 0
@@ -43,21 +58,29 @@ func (d *DigestSHA256) Reset() {
 func (d *DigestSHA256) Write(p []byte) (nn int, err error) {
 	nn = len(p)
 	d.len += uint64(nn)
-	for _, c := range p {
-		d.x[d.nx] = c
-		d.nx++
-		if d.nx == 64 {
-			d.processBlock(d.x[:])
-			d.nx = 0
+	// сначала дополняем неполный блок из буфера, затем обрабатываем полные блоки прямо из p
+	if d.nx > 0 {
+		n := copy(d.x[d.nx:], p)
+		d.nx += n
+		p = p[n:]
+		if d.nx < 64 {
+			return nn, nil
 		}
+		d.processBlock(d.x[:])
+		d.nx = 0
 	}
+	for len(p) >= 64 {
+		d.processBlock(p[:64])
+		p = p[64:]
+	}
+	d.nx = copy(d.x[:], p)
 	return nn, nil
 }
 
 func (d *DigestSHA256) Sum(b []byte) []byte {
 	d0 := *d // Копируем состояние, чтобы не портить текущее
 	hash := d0.checkSum()
-	return append(b, hash[:]...)
+	return append(b, hash[:d.Size()]...)
 }
 
 func (d *DigestSHA256) checkSum() [32]byte {
@@ -132,6 +155,9 @@ func (d *DigestSHA256) processBlock(p []byte) {
 
 // Size returns the number of bytes Sum will return.
 func (d *DigestSHA256) Size() int {
+	if d.is224 {
+		return 28
+	}
 	return 32
 }
 
@@ -50,3 +50,32 @@ func TestSHA256_MultiWrite(t *testing.T) {
 		t.Errorf("SHA256 multi-write mismatch:\ngot:  %x\nwant: %x", got, want)
 	}
 }
+
+// Запись кусками разной длины: неполный буфер, полные блоки из входа и хвост.
+func TestSHA256_UnevenWrites(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i * 31)
+	}
+	want := sha256.Sum256(data)
+
+	for _, step := range []int{1, 3, 63, 64, 65, 127, 500} {
+		h := NewSHA256()
+		for off := 0; off < len(data); off += step {
+			h.Write(data[off:min(off+step, len(data))])
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("step %d: got %x, want %x", step, got, want)
+		}
+	}
+}
+
+func BenchmarkSHA256_1M(b *testing.B) {
+	data := make([]byte, 1<<20)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		h := NewSHA256()
+		h.Write(data)
+		h.Sum(nil)
+	}
+}
@@ -0,0 +1,234 @@
+package hash
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// SHA-3 и SHAKE (FIPS 202) на перестановке Keccak-f[1600]; cSHAKE — NIST SP 800-185.
+// Губка: rate байт состояния смешиваются с входом, после дополнения выход читается
+// блоками по rate байт. Варианты различаются только rate, байтом домена и длиной выхода.
+
+var keccakRC = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// keccakRho: сдвиги rho для дорожки x+5y.
+var keccakRho = [25]int{
+	0, 1, 62, 28, 27,
+	36, 44, 6, 55, 20,
+	3, 10, 43, 25, 39,
+	41, 45, 15, 21, 8,
+	18, 2, 61, 56, 14,
+}
+
+// keccakF1600: 24 раунда theta, rho, pi, chi, iota над дорожками a[x+5y].
+func keccakF1600(a *[25]uint64) {
+	var c, d [5]uint64
+	var b [25]uint64
+	for round := 0; round < 24; round++ {
+		for x := 0; x < 5; x++ {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d[x] = c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+		}
+		for i := range a {
+			a[i] ^= d[i%5]
+		}
+		// rho и pi: дорожка (x, y) переходит в (y, 2x+3y)
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = bits.RotateLeft64(a[x+5*y], keccakRho[x+5*y])
+			}
+		}
+		for y := 0; y < 25; y += 5 {
+			for x := 0; x < 5; x++ {
+				a[y+x] = b[y+x] ^ (^b[y+(x+1)%5] & b[y+(x+2)%5])
+			}
+		}
+		a[0] ^= keccakRC[round]
+	}
+}
+
+// Байты домена с первым битом дополнения pad10*1 (FIPS 202, B.2; SP 800-185, 3.3).
+const (
+	dsSHA3   = 0x06
+	dsSHAKE  = 0x1f
+	dsCSHAKE = 0x04
+)
+
+// sponge: общее состояние для SHA-3, SHAKE и cSHAKE.
+type sponge struct {
+	a         [25]uint64
+	buf       [200]byte // входной или выходной блок (rate байт)
+	n         int       // заполнено байт при впитывании / выдано байт при выжимании
+	rate      int
+	ds        byte
+	squeezing bool
+	initial   []byte // bytepad(encode_string(N) || encode_string(S)) для cSHAKE
+}
+
+func (s *sponge) reset() {
+	s.a = [25]uint64{}
+	s.n = 0
+	s.squeezing = false
+	if len(s.initial) > 0 {
+		s.write(s.initial)
+	}
+}
+
+func (s *sponge) permute() {
+	for i := 0; i < s.rate/8; i++ {
+		s.a[i] ^= binary.LittleEndian.Uint64(s.buf[8*i:])
+	}
+	keccakF1600(&s.a)
+}
+
+func (s *sponge) write(p []byte) {
+	if s.squeezing {
+		panic("hash: write after read")
+	}
+	for len(p) > 0 {
+		k := copy(s.buf[s.n:s.rate], p)
+		s.n += k
+		p = p[k:]
+		if s.n == s.rate {
+			s.permute()
+			s.n = 0
+		}
+	}
+}
+
+// pad завершает впитывание: ds || 0..0 || 0x80 и переход к выжиманию.
+func (s *sponge) pad() {
+	for i := s.n; i < s.rate; i++ {
+		s.buf[i] = 0
+	}
+	s.buf[s.n] ^= s.ds
+	s.buf[s.rate-1] ^= 0x80
+	s.permute()
+	s.squeezing = true
+	s.fill()
+}
+
+func (s *sponge) fill() {
+	for i := 0; i < s.rate/8; i++ {
+		binary.LittleEndian.PutUint64(s.buf[8*i:], s.a[i])
+	}
+	s.n = 0
+}
+
+func (s *sponge) read(out []byte) {
+	if !s.squeezing {
+		s.pad()
+	}
+	for len(out) > 0 {
+		if s.n == s.rate {
+			keccakF1600(&s.a)
+			s.fill()
+		}
+		k := copy(out, s.buf[s.n:s.rate])
+		s.n += k
+		out = out[k:]
+	}
+}
+
+// DigestSHA3: SHA3-224/256/384/512 как hash.Hash.
+type DigestSHA3 struct {
+	s    sponge
+	size int
+}
+
+func newSHA3(size int) *DigestSHA3 {
+	return &DigestSHA3{s: sponge{rate: 200 - 2*size, ds: dsSHA3}, size: size}
+}
+
+func NewSHA3_224() *DigestSHA3 { return newSHA3(28) }
+func NewSHA3_256() *DigestSHA3 { return newSHA3(32) }
+func NewSHA3_384() *DigestSHA3 { return newSHA3(48) }
+func NewSHA3_512() *DigestSHA3 { return newSHA3(64) }
+
+func (d *DigestSHA3) Write(p []byte) (int, error) {
+	d.s.write(p)
+	return len(p), nil
+}
+
+func (d *DigestSHA3) Sum(b []byte) []byte {
+	s := d.s // Копируем состояние, чтобы не портить текущее
+	out := make([]byte, d.size)
+	s.read(out)
+	return append(b, out...)
+}
+
+func (d *DigestSHA3) Reset()         { d.s.reset() }
+func (d *DigestSHA3) Size() int      { return d.size }
+func (d *DigestSHA3) BlockSize() int { return d.s.rate }
+
+// XOF: SHAKE128/256 и cSHAKE128/256 — функции с выходом произвольной длины.
+// Write допустим только до первого Read; Read можно вызывать сколько угодно раз.
+type XOF struct {
+	s sponge
+}
+
+func NewSHAKE128() *XOF { return &XOF{s: sponge{rate: 168, ds: dsSHAKE}} }
+func NewSHAKE256() *XOF { return &XOF{s: sponge{rate: 136, ds: dsSHAKE}} }
+
+// NewCSHAKE128: cSHAKE128(X, L, N, S); при пустых N и S совпадает с SHAKE128.
+func NewCSHAKE128(n, s []byte) *XOF { return newCSHAKE(168, n, s) }
+
+// NewCSHAKE256: cSHAKE256(X, L, N, S); при пустых N и S совпадает с SHAKE256.
+func NewCSHAKE256(n, s []byte) *XOF { return newCSHAKE(136, n, s) }
+
+func newCSHAKE(rate int, n, s []byte) *XOF {
+	if len(n) == 0 && len(s) == 0 {
+		return &XOF{s: sponge{rate: rate, ds: dsSHAKE}}
+	}
+	x := &XOF{s: sponge{rate: rate, ds: dsCSHAKE}}
+	x.s.initial = bytepad(append(encodeString(n), encodeString(s)...), rate)
+	x.s.reset()
+	return x
+}
+
+func (x *XOF) Write(p []byte) (int, error) {
+	x.s.write(p)
+	return len(p), nil
+}
+
+func (x *XOF) Read(out []byte) (int, error) {
+	x.s.read(out)
+	return len(out), nil
+}
+
+func (x *XOF) Reset() { x.s.reset() }
+
+// leftEncode: SP 800-185, 2.3.1 — число байт, затем само значение big-endian.
+func leftEncode(v uint64) []byte {
+	var b [9]byte
+	binary.BigEndian.PutUint64(b[1:], v)
+	i := 1
+	for i < 8 && b[i] == 0 {
+		i++
+	}
+	b[i-1] = byte(9 - i)
+	return append([]byte(nil), b[i-1:]...)
+}
+
+// encodeString: left_encode(длина в битах) || S.
+func encodeString(s []byte) []byte {
+	return append(leftEncode(uint64(len(s))*8), s...)
+}
+
+// bytepad: left_encode(w) || X, дополненное нулями до кратного w.
+func bytepad(x []byte, w int) []byte {
+	out := append(leftEncode(uint64(w)), x...)
+	for len(out)%w != 0 {
+		out = append(out, 0)
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// FIPS 202: пустое сообщение, "abc" и 1600 бит 0xa3 (примеры NIST).
+var sha3Messages = [][]byte{nil, []byte("abc"), bytes.Repeat([]byte{0xa3}, 200)}
+
+func TestSHA3_FIPS202(t *testing.T) {
+	vectors := map[string][3]string{
+		"sha3-224": {
+			"6b4e03423667dbb73b6e15454f0eb1abd4597f9a1b078e3f5b5a6bc7",
+			"e642824c3f8cf24ad09234ee7d3c766fc9a3a5168d0c94ad73b46fdf",
+			"9376816aba503f72f96ce7eb65ac095deee3be4bf9bbc2a1cb7e11e0",
+		},
+		"sha3-256": {
+			"a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a",
+			"3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532",
+			"79f38adec5c20307a98ef76e8324afbfd46cfd81b22e3973c65fa1bd9de31787",
+		},
+		"sha3-384": {
+			"0c63a75b845e4f7d01107d852e4c2485c51a50aaaa94fc61995e71bbee983a2ac3713831264adb47fb6bd1e058d5f004",
+			"ec01498288516fc926459f58e2c6ad8df9b473cb0fc08c2596da7cf0e49be4b298d88cea927ac7f539f1edf228376d25",
+			"1881de2ca7e41ef95dc4732b8f5f002b189cc1e42b74168ed1732649ce1dbcdd76197a31fd55ee989f2d7050dd473e8f",
+		},
+		"sha3-512": {
+			"a69f73cca23a9ac5c8b567dc185a756e97c982164fe25859e0d1dcc1475c80a615b2123af1f5f94c11e3e9402c3ac558f500199d95b6d3e301758586281dcd26",
+			"b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0",
+			"e76dfad22084a8b1467fcf2ffa58361bec7628edf5f3fdc0e4805dc48caeeca81b7c13c30adf52a3659584739a2df46be589c51ca1a4a8416df6545a1ce8ba00",
+		},
+	}
+
+	for name, want := range vectors {
+		newHash, err := Digest(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, msg := range sha3Messages {
+			h := newHash()
+			// по байту — чтобы пройти через буфер губки
+			for j := range msg {
+				h.Write(msg[j : j+1])
+			}
+			if got := hex.EncodeToString(h.Sum(nil)); got != want[i] {
+				t.Errorf("%s, message %d: got %s, want %s", name, i, got, want[i])
+			}
+		}
+	}
+}
+
+func TestSHAKE_FIPS202(t *testing.T) {
+	vectors := map[string][3]string{
+		"shake128": {
+			"7f9c2ba4e88f827d616045507605853ed73b8093f6efbc88eb1a6eacfa66ef26",
+			"5881092dd818bf5cf8a3ddb793fbcba74097d5c526a6d35f97b83351940f2cc8",
+			"131ab8d2b594946b9c81333f9bb6e0ce75c3b93104fa3469d3917457385da037",
+		},
+		"shake256": {
+			"46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82b50c27646ed5762fd75dc4ddd8c0f200cb05019d67b592f6fc821c49479ab48640292eacb3b7c4be",
+			"483366601360a8771c6863080cc4114d8db44530f8f1e1ee4f94ea37e78b5739d5a15bef186a5386c75744c0527e1faa9f8726e462a12a4feb06bd8801e751e4",
+			"cd8a920ed141aa0407a22d59288652e9d9f1a7ee0c1e7c1ca699424da84a904d2d700caae7396ece96604440577da4f3aa22aeb8857f961c4cd8e06f0ae6610b",
+		},
+	}
+
+	for name, want := range vectors {
+		for i, msg := range sha3Messages {
+			x, n, err := NewXOFByName(name, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			x.Write(msg)
+			out := make([]byte, n)
+			x.Read(out)
+			if got := hex.EncodeToString(out); got != want[i] {
+				t.Errorf("%s, message %d: got %s, want %s", name, i, got, want[i])
+			}
+		}
+	}
+
+	// выход читается по частям так же, как целиком; префикс не зависит от длины
+	a, b := NewSHAKE128(), NewSHAKE128()
+	long, parts := make([]byte, 1000), make([]byte, 1000)
+	a.Read(long)
+	for off := 0; off < len(parts); off += 167 {
+		b.Read(parts[off:min(off+167, len(parts))])
+	}
+	if !bytes.Equal(long, parts) {
+		t.Fatal("SHAKE128: chunked read differs")
+	}
+}
+
+func TestCSHAKE_SP800185(t *testing.T) {
+	// NIST SP 800-185, примеры cSHAKE128 #1 и cSHAKE256 #4
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	x := NewCSHAKE128(nil, []byte("Email Signature"))
+	x.Write(data[:4])
+	out := make([]byte, 32)
+	x.Read(out)
+	if got := hex.EncodeToString(out); got != "c1c36925b6409a04f1b504fcbca9d82b4017277cb5ed2b2065fc1d3814d5aaf5" {
+		t.Errorf("cSHAKE128: got %s", got)
+	}
+
+	x = NewCSHAKE256(nil, []byte("Email Signature"))
+	x.Write(data)
+	out = make([]byte, 64)
+	x.Read(out)
+	if got := hex.EncodeToString(out); got != "07dc27b11e51fbac75bc7b3c1d983e8b4b85fb1defaf218912ac86430273091727f42b17ed1df63e8ec118f04b23633c1dfb1574c8fb55cb45da8e25afb092bb" {
+		t.Errorf("cSHAKE256: got %s", got)
+	}
+
+	// пустые N и S — обычный SHAKE
+	a, b := NewCSHAKE256(nil, nil), NewSHAKE256()
+	ao, bo := make([]byte, 64), make([]byte, 64)
+	a.Read(ao)
+	b.Read(bo)
+	if !bytes.Equal(ao, bo) {
+		t.Error("cSHAKE256 with empty N and S differs from SHAKE256")
+	}
+}
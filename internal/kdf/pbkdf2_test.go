@@ -4,6 +4,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"testing"
+
+	myhash "cryptcore/internal/hash"
 )
 
 func TestPBKDF2(t *testing.T) {
@@ -32,3 +34,27 @@ func TestPBKDF2(t *testing.T) {
 		}
 	}
 }
+
+func TestPBKDF2_SHA512Family(t *testing.T) {
+	// PBKDF2-HMAC-SHA512/SHA384, password/salt (совпадает с OpenSSL и Python hashlib)
+	cases := []struct {
+		hash   string
+		iter   int
+		keyLen int
+		want   string
+	}{
+		{"sha512", 1, 64, "867f70cf1ade02cff3752599a3a53dc4af34c7a669815ae5d513554e1c8cf252c02d470a285a0501bad999bfe943c08f050235d7d68b1da55e63f73b60a57fce"},
+		{"sha512", 2, 64, "e1d9c16aa681708a45f5c7c4e215ceb66e011a2e9f0040713f18aefdb866d53cf76cab2868a39b9f7840edce4fef5a82be67335c77a6068e04112754f27ccf4e"},
+		{"sha384", 2, 48, "54f775c6d790f21930459162fc535dbf04a939185127016a04176a0730c6f1f4fb48832ad1261baadd2cedd50814b1c8"},
+	}
+	for _, c := range cases {
+		newHash, err := myhash.Digest(c.hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dk := Key(newHash, []byte("password"), []byte("salt"), c.iter, c.keyLen)
+		if got := hex.EncodeToString(dk); got != c.want {
+			t.Fatalf("%s/%d: got %s, want %s", c.hash, c.iter, got, c.want)
+		}
+	}
+}
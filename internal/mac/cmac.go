@@ -0,0 +1,91 @@
+package mac
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"hash"
+
+	"cryptcore/internal/blockcipher"
+)
+
+const CMACSize = aes.BlockSize
+
+// CMAC — MAC на основе блочного шифра (NIST SP 800-38B, RFC 4493) над AES.
+// Последний блок сообщения не обрабатывается до Sum: только тогда известно,
+// полный он (xor K1) или дополняется 10..0 (xor K2).
+type CMAC struct {
+	block  cipher.Block
+	k1, k2 [aes.BlockSize]byte
+	x      [aes.BlockSize]byte // значение цепочки CBC-MAC
+	buf    [aes.BlockSize]byte
+	nbuf   int
+}
+
+// NewCMAC возвращает AES-CMAC; длина ключа выбирает AES-128/192/256.
+func NewCMAC(key []byte) (hash.Hash, error) {
+	block, err := blockcipher.NewAES(key)
+	if err != nil {
+		return nil, err
+	}
+	c := &CMAC{block: block}
+	// подключи: L = E_K(0^128), K1 = dbl(L), K2 = dbl(K1)
+	block.Encrypt(c.k1[:], c.k1[:])
+	Dbl(c.k1[:])
+	c.k2 = c.k1
+	Dbl(c.k2[:])
+	return c, nil
+}
+
+func (c *CMAC) Reset() {
+	c.x = [aes.BlockSize]byte{}
+	c.nbuf = 0
+}
+
+func (c *CMAC) Write(data []byte) (int, error) {
+	n := len(data)
+	for len(data) > 0 {
+		// полный буфер обрабатываем, только когда пришли ещё данные: он не последний
+		if c.nbuf == aes.BlockSize {
+			for i := range c.x {
+				c.x[i] ^= c.buf[i]
+			}
+			c.block.Encrypt(c.x[:], c.x[:])
+			c.nbuf = 0
+		}
+		k := copy(c.buf[c.nbuf:], data)
+		c.nbuf += k
+		data = data[k:]
+	}
+	return n, nil
+}
+
+// Sum не меняет состояние: можно продолжать Write.
+func (c *CMAC) Sum(b []byte) []byte {
+	last := c.buf
+	sub := &c.k1
+	if c.nbuf < aes.BlockSize {
+		last[c.nbuf] = 0x80
+		for i := c.nbuf + 1; i < aes.BlockSize; i++ {
+			last[i] = 0
+		}
+		sub = &c.k2
+	}
+	var tag [aes.BlockSize]byte
+	for i := range tag {
+		tag[i] = c.x[i] ^ last[i] ^ sub[i]
+	}
+	c.block.Encrypt(tag[:], tag[:])
+	return append(b, tag[:]...)
+}
+
+func (c *CMAC) Size() int      { return CMACSize }
+func (c *CMAC) BlockSize() int { return aes.BlockSize }
+
+// Dbl умножает 128-битный блок на x в GF(2^128) (сдвиг влево, при переносе xor 0x87).
+func Dbl(b []byte) {
+	carry := b[0] >> 7
+	for i := 0; i < len(b)-1; i++ {
+		b[i] = b[i]<<1 | b[i+1]>>7
+	}
+	b[len(b)-1] = b[len(b)-1]<<1 ^ 0x87*carry
+}
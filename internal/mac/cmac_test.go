@@ -0,0 +1,75 @@
+package mac
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+const cmacMessage = "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710"
+
+func TestCMAC_Subkeys_RFC4493(t *testing.T) {
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	h, err := NewCMAC(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := h.(*CMAC)
+	if got := hex.EncodeToString(c.k1[:]); got != "fbeed618357133667c85e08f7236a8de" {
+		t.Errorf("K1 = %s", got)
+	}
+	if got := hex.EncodeToString(c.k2[:]); got != "f7ddac306ae266ccf90bc11ee46d513b" {
+		t.Errorf("K2 = %s", got)
+	}
+}
+
+func TestCMAC_Vectors(t *testing.T) {
+	// RFC 4493, 4 (AES-128) и NIST SP 800-38B, D.3 (AES-256): сообщения длиной 0, 16, 40 и 64 байта
+	cases := []struct {
+		key  string
+		tags [4]string
+	}{
+		{
+			key: "2b7e151628aed2a6abf7158809cf4f3c",
+			tags: [4]string{
+				"bb1d6929e95937287fa37d129b756746",
+				"070a16b46b4d4144f79bdd9dd04a287c",
+				"dfa66747de9ae63030ca32611497c827",
+				"51f0bebf7e3b9d92fc49741779363cfe",
+			},
+		},
+		{
+			key: "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4",
+			tags: [4]string{
+				"028962f61b7bf89efc6b551f4667d983",
+				"28a7023f452e8f82bd4bf28d8c37c35c",
+				"aaf3d8f1de5640c232f5b169b9c911e6",
+				"e1992190549f6ed5696a2c056c315410",
+			},
+		},
+	}
+	msg, _ := hex.DecodeString(cmacMessage)
+
+	for _, c := range cases {
+		key, _ := hex.DecodeString(c.key)
+		h, err := NewCMAC(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, n := range []int{0, 16, 40, 64} {
+			h.Reset()
+			// побайтовая запись: неполный и полный последний блок не должны путаться
+			for _, b := range msg[:n] {
+				h.Write([]byte{b})
+			}
+			if got := hex.EncodeToString(h.Sum(nil)); got != c.tags[i] {
+				t.Errorf("key %d bits, len %d: got %s, want %s", len(key)*8, n, got, c.tags[i])
+			}
+		}
+	}
+}
+
+func TestCMAC_BadKey(t *testing.T) {
+	if _, err := NewCMAC(make([]byte, 15)); err == nil {
+		t.Fatal("expected error for 15-byte key")
+	}
+}
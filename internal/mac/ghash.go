@@ -0,0 +1,72 @@
+package mac
+
+import "encoding/binary"
+
+// GHASH — универсальная хеш-функция GCM (NIST SP 800-38D, 6.4) над GF(2^128).
+// Используется GCM в internal/crypto и GMAC.
+type GHASH struct {
+	h, y ghashElement
+}
+
+type ghashElement struct {
+	hi, lo uint64
+}
+
+// NewGHASH: h — 16-байтный подключ H = E(K, 0^128).
+func NewGHASH(h []byte) *GHASH {
+	return &GHASH{h: ghashElement{
+		hi: binary.BigEndian.Uint64(h[:8]),
+		lo: binary.BigEndian.Uint64(h[8:16]),
+	}}
+}
+
+func (g *GHASH) Reset() { g.y = ghashElement{} }
+
+// Update обрабатывает data поблочно, последний неполный блок дополняется нулями.
+func (g *GHASH) Update(data []byte) {
+	for len(data) > 0 {
+		var blk [16]byte
+		n := copy(blk[:], data)
+		data = data[n:]
+
+		g.y.hi ^= binary.BigEndian.Uint64(blk[:8])
+		g.y.lo ^= binary.BigEndian.Uint64(blk[8:])
+		g.mul()
+	}
+}
+
+// UpdateLengths добавляет завершающий блок len(A) || len(C) (в битах).
+func (g *GHASH) UpdateLengths(aBits, cBits uint64) {
+	g.y.hi ^= aBits
+	g.y.lo ^= cBits
+	g.mul()
+}
+
+// Digest записывает текущее значение Y в dst[:16].
+func (g *GHASH) Digest(dst []byte) {
+	binary.BigEndian.PutUint64(dst[:8], g.y.hi)
+	binary.BigEndian.PutUint64(dst[8:16], g.y.lo)
+}
+
+// mul: y = y * H (SP 800-38D, алгоритм 1), без ветвлений по данным.
+func (g *GHASH) mul() {
+	var z ghashElement
+	v := g.h
+
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = (g.y.hi >> (63 - i)) & 1
+		} else {
+			bit = (g.y.lo >> (127 - i)) & 1
+		}
+		mask := -bit
+		z.hi ^= v.hi & mask
+		z.lo ^= v.lo & mask
+
+		lsb := v.lo & 1
+		v.lo = v.lo>>1 | v.hi<<63
+		v.hi = v.hi>>1 ^ (0xe100000000000000 & -lsb)
+	}
+	g.y = z
+}
@@ -0,0 +1,94 @@
+package mac
+
+import (
+	"crypto/cipher"
+	"errors"
+	"hash"
+
+	"cryptcore/internal/blockcipher"
+)
+
+const GMACTagSize = 16
+
+// GMAC — GCM с пустым открытым текстом (NIST SP 800-38D): все данные идут как AAD,
+// тег = E(K, J0) xor GHASH(A || len(A) || 0). Пара (ключ, nonce) одноразовая:
+// повтор nonce под тем же ключом раскрывает H и позволяет подделывать теги.
+type GMAC struct {
+	block cipher.Block
+	ghash *GHASH
+	ekj0  [16]byte // E(K, J0)
+	buf   [16]byte
+	nbuf  int
+	n     uint64 // длина данных в байтах
+}
+
+// NewGMAC: ключ AES 16/24/32 байта; nonce любой непустой длины,
+// 12 байт — рекомендуемый размер, другие длины хешируются в J0 через GHASH.
+func NewGMAC(key, nonce []byte) (hash.Hash, error) {
+	block, err := blockcipher.NewAES(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) == 0 {
+		return nil, errors.New("gmac nonce must not be empty")
+	}
+
+	var h [16]byte
+	block.Encrypt(h[:], h[:])
+	g := &GMAC{block: block, ghash: NewGHASH(h[:])}
+
+	var j0 [16]byte
+	if len(nonce) == 12 {
+		copy(j0[:], nonce)
+		j0[15] = 1
+	} else {
+		g.ghash.Update(nonce)
+		g.ghash.UpdateLengths(0, uint64(len(nonce))*8)
+		g.ghash.Digest(j0[:])
+		g.ghash.Reset()
+	}
+	block.Encrypt(g.ekj0[:], j0[:])
+	return g, nil
+}
+
+func (g *GMAC) Reset() {
+	g.ghash.Reset()
+	g.nbuf = 0
+	g.n = 0
+}
+
+func (g *GMAC) Write(data []byte) (int, error) {
+	n := len(data)
+	g.n += uint64(n)
+	if g.nbuf > 0 {
+		k := copy(g.buf[g.nbuf:], data)
+		g.nbuf += k
+		data = data[k:]
+		if g.nbuf < 16 {
+			return n, nil
+		}
+		g.ghash.Update(g.buf[:])
+		g.nbuf = 0
+	}
+	full := len(data) &^ 15
+	g.ghash.Update(data[:full])
+	g.nbuf = copy(g.buf[:], data[full:])
+	return n, nil
+}
+
+// Sum не меняет состояние: можно продолжать Write.
+func (g *GMAC) Sum(b []byte) []byte {
+	y := *g.ghash
+	y.Update(g.buf[:g.nbuf])
+	y.UpdateLengths(g.n*8, 0)
+
+	var tag [16]byte
+	y.Digest(tag[:])
+	for i := range tag {
+		tag[i] ^= g.ekj0[i]
+	}
+	return append(b, tag[:]...)
+}
+
+func (g *GMAC) Size() int      { return GMACTagSize }
+func (g *GMAC) BlockSize() int { return 16 }
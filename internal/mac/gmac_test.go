@@ -0,0 +1,76 @@
+package mac
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+)
+
+func TestGMAC_Vectors(t *testing.T) {
+	cases := []struct {
+		name, key, nonce, aad, tag string
+	}{
+		{
+			// McGrew & Viega, GCM test case 1: пустые данные
+			name:  "gcm-1",
+			key:   "00000000000000000000000000000000",
+			nonce: "000000000000000000000000",
+			tag:   "58e2fccefa7e3061367f1d57a4e7455a",
+		},
+		{
+			// NIST CAVS gcmEncryptExtIV128, PTlen = 0, AADlen = 128, Count 0
+			name:  "cavs",
+			key:   "77be63708971c4e240d1cb79e8d77feb",
+			nonce: "e0e00f19fed7ba0136a797f3",
+			aad:   "7a43ec1d9c0a5a78a0b16533a6213cab",
+			tag:   "209fcc8d3675ed938e9c7166709dd946",
+		},
+	}
+	for _, c := range cases {
+		key, _ := hex.DecodeString(c.key)
+		nonce, _ := hex.DecodeString(c.nonce)
+		aad, _ := hex.DecodeString(c.aad)
+		g, err := NewGMAC(key, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Write(aad)
+		if got := hex.EncodeToString(g.Sum(nil)); got != c.tag {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.tag)
+		}
+	}
+}
+
+// Сверка с crypto/cipher: GMAC = тег GCM с пустым открытым текстом,
+// в том числе для nonce не из 12 байт и для записи кусками.
+func TestGMAC_MatchesStdlibGCM(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i * 13)
+	}
+	block, _ := aes.NewCipher(key)
+
+	for _, nonceSize := range []int{12, 8, 16, 20} {
+		nonce := data[:nonceSize]
+		std, err := cipher.NewGCMWithNonceSize(block, nonceSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range []int{0, 1, 16, 17, 255} {
+			want := std.Seal(nil, nonce, nil, data[:n])
+
+			g, err := NewGMAC(key, nonce)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for off := 0; off < n; off += 7 {
+				g.Write(data[off:min(off+7, n)])
+			}
+			if got := g.Sum(nil); hex.EncodeToString(got) != hex.EncodeToString(want) {
+				t.Fatalf("nonce %d, len %d: got %x, want %x", nonceSize, n, got, want)
+			}
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package mac
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"testing"
+
+	myhash "cryptcore/internal/hash"
 )
 
 func TestHMAC_SHA256_RFC4231_Case1(t *testing.T) {
@@ -21,3 +24,59 @@ func TestHMAC_SHA256_RFC4231_Case1(t *testing.T) {
 		t.Errorf("got %s, want %s", hexSum, expected)
 	}
 }
+
+func TestHMAC_SHA2Family_RFC4231(t *testing.T) {
+	// RFC 4231 Test Case 1 и 6 (ключ длиннее блока) над собственными SHA-224/384/512
+	case1 := map[string]string{
+		"sha224": "896fb1128abbdf196832107cd49df33f47b4b1169912ba4f53684b22",
+		"sha384": "afd03944d84895626b0825f4ab46907f15f9dadbe4101ec682aa034c7cebc59cfaea9ea9076ede7f4af152e8b2fa9cb6",
+		"sha512": "87aa7cdea5ef619d4ff0b4241a1d6cb02379f4e2ce4ec2787ad0b30545e17cdedaa833b7d6b8a702038b274eaea3f4e4be9d914eeb61f1702e696c203a126854",
+	}
+	case6 := map[string]string{
+		"sha224": "95e9a0db962095adaebe9b2d6f0dbce2d499f112f2d2b7273fa6870e",
+		"sha384": "4ece084485813e9088d2c63a041bc5b44f9ef1012a2b588f3cd11f05033ac4c60c2ef6ab4030fe8296248df163f44952",
+		"sha512": "80b24263c7c1a3ebb71493c1dd7be8b49b46d1f41b4aeec1121b013783f8f3526b56d037e05f2598bd0fd2215d6a1e5295e64f73f63f0aec8b915a985d786598",
+	}
+
+	for name := range case1 {
+		newHash, err := myhash.Digest(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := New(newHash, bytes.Repeat([]byte{0x0b}, 20))
+		h.Write([]byte("Hi There"))
+		if got := hex.EncodeToString(h.Sum(nil)); got != case1[name] {
+			t.Errorf("%s case 1: got %s, want %s", name, got, case1[name])
+		}
+
+		h = New(newHash, bytes.Repeat([]byte{0xaa}, 131))
+		h.Write([]byte("Test Using Larger Than Block-Size Key - Hash Key First"))
+		if got := hex.EncodeToString(h.Sum(nil)); got != case6[name] {
+			t.Errorf("%s case 6: got %s, want %s", name, got, case6[name])
+		}
+	}
+}
+
+func TestHMAC_SHA3(t *testing.T) {
+	// блок HMAC-SHA3 равен rate губки (136 байт для SHA3-256, 72 для SHA3-512)
+	cases := []struct {
+		hash string
+		key  []byte
+		data string
+		want string
+	}{
+		{"sha3-256", bytes.Repeat([]byte{0x0b}, 20), "Hi There", "ba85192310dffa96e2a3a40e69774351140bb7185e1202cdcc917589f95e16bb"},
+		{"sha3-512", bytes.Repeat([]byte{0xaa}, 200), "Test Using Larger Than Block-Size Key - Hash Key First", "fafc7b7fe3332ce153966b27f6586fa5b49ec5d8dff3d7fd26a011451ca4c9de437913879159d9c5181a9a6f377ef18b48399756decea695b04fe90a9d3b93d1"},
+	}
+	for _, c := range cases {
+		newHash, err := myhash.Digest(c.hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := New(newHash, c.key)
+		h.Write([]byte(c.data))
+		if got := hex.EncodeToString(h.Sum(nil)); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.hash, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package mac
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/bits"
+)
+
+const (
+	Poly1305KeySize = 32
+	Poly1305TagSize = 16
+)
+
+// Poly1305 — одноразовый MAC из RFC 8439. Ключ (r, s) нельзя использовать дважды.
+// Аккумулятор h = h0 + h1<<64 + h2<<128 хранится по модулю 2^130 - 5.
+type Poly1305 struct {
+	r0, r1     uint64
+	s0, s1     uint64
+	h0, h1, h2 uint64
+	buf        [16]byte
+	nbuf       int
+	key        [Poly1305KeySize]byte
+}
+
+// NewPoly1305 возвращает Poly1305 с 32-байтным одноразовым ключом r||s.
+func NewPoly1305(key []byte) (hash.Hash, error) {
+	if len(key) != Poly1305KeySize {
+		return nil, errors.New("poly1305 key must be 32 bytes")
+	}
+	p := &Poly1305{}
+	copy(p.key[:], key)
+	p.Reset()
+	return p, nil
+}
+
+func (p *Poly1305) Reset() {
+	// clamp(r): r &= 0x0ffffffc0ffffffc0ffffffc0fffffff
+	p.r0 = binary.LittleEndian.Uint64(p.key[0:8]) & 0x0ffffffc0fffffff
+	p.r1 = binary.LittleEndian.Uint64(p.key[8:16]) & 0x0ffffffc0ffffffc
+	p.s0 = binary.LittleEndian.Uint64(p.key[16:24])
+	p.s1 = binary.LittleEndian.Uint64(p.key[24:32])
+	p.h0, p.h1, p.h2 = 0, 0, 0
+	p.nbuf = 0
+}
+
+func (p *Poly1305) Write(data []byte) (int, error) {
+	n := len(data)
+	if p.nbuf > 0 {
+		k := copy(p.buf[p.nbuf:], data)
+		p.nbuf += k
+		data = data[k:]
+		if p.nbuf < 16 {
+			return n, nil
+		}
+		p.block(p.buf[:], 1)
+		p.nbuf = 0
+	}
+	for len(data) >= 16 {
+		p.block(data[:16], 1)
+		data = data[16:]
+	}
+	p.nbuf = copy(p.buf[:], data)
+	return n, nil
+}
+
+func (p *Poly1305) Sum(b []byte) []byte {
+	d := *p // финализация не портит текущее состояние
+	if d.nbuf > 0 {
+		var last [16]byte
+		copy(last[:], d.buf[:d.nbuf])
+		last[d.nbuf] = 1 // неполный блок дополняется 0x01, без бита 2^128
+		d.block(last[:], 0)
+	}
+
+	// h mod p: если h - p не уходит в минус, берём h - p
+	t0, borrow := bits.Sub64(d.h0, 0xfffffffffffffffb, 0)
+	t1, borrow := bits.Sub64(d.h1, 0xffffffffffffffff, borrow)
+	_, borrow = bits.Sub64(d.h2, 3, borrow)
+	mask := borrow - 1 // все единицы, если h >= p
+	h0 := d.h0&^mask | t0&mask
+	h1 := d.h1&^mask | t1&mask
+
+	// tag = (h + s) mod 2^128
+	h0, carry := bits.Add64(h0, d.s0, 0)
+	h1, _ = bits.Add64(h1, d.s1, carry)
+
+	var tag [Poly1305TagSize]byte
+	binary.LittleEndian.PutUint64(tag[0:8], h0)
+	binary.LittleEndian.PutUint64(tag[8:16], h1)
+	return append(b, tag[:]...)
+}
+
+func (p *Poly1305) Size() int      { return Poly1305TagSize }
+func (p *Poly1305) BlockSize() int { return 16 }
+
+// block: h = (h + m + hibit*2^128) * r mod 2^130 - 5.
+func (p *Poly1305) block(m []byte, hibit uint64) {
+	var c uint64
+	h0, h1, h2 := p.h0, p.h1, p.h2
+	h0, c = bits.Add64(h0, binary.LittleEndian.Uint64(m[0:8]), 0)
+	h1, c = bits.Add64(h1, binary.LittleEndian.Uint64(m[8:16]), c)
+	h2 += c + hibit
+
+	// h * r; после clamp старшие биты r0, r1 нулевые, поэтому h2*r влезает в 64 бита
+	h0r0hi, h0r0lo := bits.Mul64(h0, p.r0)
+	h1r0hi, h1r0lo := bits.Mul64(h1, p.r0)
+	h0r1hi, h0r1lo := bits.Mul64(h0, p.r1)
+	h1r1hi, h1r1lo := bits.Mul64(h1, p.r1)
+	h2r0 := h2 * p.r0
+	h2r1 := h2 * p.r1
+
+	m1lo, c := bits.Add64(h1r0lo, h0r1lo, 0)
+	m1hi, _ := bits.Add64(h1r0hi, h0r1hi, c)
+	m2lo, c := bits.Add64(h2r0, h1r1lo, 0)
+	m2hi, _ := bits.Add64(0, h1r1hi, c)
+
+	t0 := h0r0lo
+	t1, c := bits.Add64(m1lo, h0r0hi, 0)
+	t2, c := bits.Add64(m2lo, m1hi, c)
+	t3, _ := bits.Add64(h2r1, m2hi, c)
+
+	// свёртка: t = low130 + 2^130*T ≡ low130 + 4T + T
+	h0, h1, h2 = t0, t1, t2&3
+	cc0, cc1 := t2&^3, t3
+	h0, c = bits.Add64(h0, cc0, 0)
+	h1, c = bits.Add64(h1, cc1, c)
+	h2 += c
+	cc0 = cc0>>2 | cc1<<62
+	cc1 >>= 2
+	h0, c = bits.Add64(h0, cc0, 0)
+	h1, c = bits.Add64(h1, cc1, c)
+	h2 += c
+
+	p.h0, p.h1, p.h2 = h0, h1, h2
+}
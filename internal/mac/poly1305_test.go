@@ -0,0 +1,77 @@
+package mac
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestPoly1305_RFC8439(t *testing.T) {
+	cases := []struct {
+		name, key, msg, tag string
+	}{
+		{
+			// RFC 8439, 2.5.2
+			name: "2.5.2",
+			key:  "85d6be7857556d337f4452fe42d506a80103808afb0db2fd4abff6af4149f51b",
+			msg:  hex.EncodeToString([]byte("Cryptographic Forum Research Group")),
+			tag:  "a8061dc1305136c6c22b8baf0c0127a9",
+		},
+		{
+			// RFC 8439, A.3 #1: нулевой ключ
+			name: "A.3-1",
+			key:  "0000000000000000000000000000000000000000000000000000000000000000",
+			msg:  "00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+			tag:  "00000000000000000000000000000000",
+		},
+		{
+			// RFC 8439, A.3 #5: h достигает p, проверка финальной редукции
+			name: "A.3-5",
+			key:  "0200000000000000000000000000000000000000000000000000000000000000",
+			msg:  "ffffffffffffffffffffffffffffffff",
+			tag:  "03000000000000000000000000000000",
+		},
+		{
+			// RFC 8439, A.3 #6
+			name: "A.3-6",
+			key:  "02000000000000000000000000000000ffffffffffffffffffffffffffffffff",
+			msg:  "02000000000000000000000000000000",
+			tag:  "03000000000000000000000000000000",
+		},
+		{
+			// RFC 8439, A.3 #7
+			name: "A.3-7",
+			key:  "0100000000000000000000000000000000000000000000000000000000000000",
+			msg:  "fffffffffffffffffffffffffffffffff0ffffffffffffffffffffffffffffff11000000000000000000000000000000",
+			tag:  "05000000000000000000000000000000",
+		},
+		{
+			// RFC 8439, A.3 #8
+			name: "A.3-8",
+			key:  "0100000000000000000000000000000000000000000000000000000000000000",
+			msg:  "fffffffffffffffffffffffffffffffffbfefefefefefefefefefefefefefefe01010101010101010101010101010101",
+			tag:  "00000000000000000000000000000000",
+		},
+	}
+
+	for _, c := range cases {
+		key, _ := hex.DecodeString(c.key)
+		msg, _ := hex.DecodeString(c.msg)
+
+		p, err := NewPoly1305(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// пишем по частям, чтобы проверить буферизацию неполных блоков
+		p.Write(msg[:len(msg)/3])
+		p.Write(msg[len(msg)/3:])
+		if got := hex.EncodeToString(p.Sum(nil)); got != c.tag {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.tag)
+		}
+	}
+}
+
+func TestPoly1305_BadKey(t *testing.T) {
+	if _, err := NewPoly1305(make([]byte, 16)); err == nil {
+		t.Fatalf("expected error for short key")
+	}
+}